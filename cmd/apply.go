@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyIntentsFilename  string
+	applyEntitiesFilename string
+	applyPrune            bool
+
+	applyCmd = &cobra.Command{
+		Use:   "apply",
+		Short: "Create, update and optionally delete intents and entities to match the given files",
+		Run: func(_ *cobra.Command, _ []string) {
+			ctx, cancel := newContext()
+			defer cancel()
+
+			intentsClient, err := dialogflow.NewIntentsClient(ctx, projectID, clientOptions()...)
+			if err != nil {
+				log.Fatalf("failed to create intents client: %v", err)
+			}
+			defer func() {
+				if err = intentsClient.Close(); err != nil {
+					log.Printf("failed to close intents client: %v", err)
+				}
+			}()
+
+			entityTypesClient, err := dialogflow.NewEntityTypesClient(ctx, projectID, clientOptions()...)
+			if err != nil {
+				log.Fatalf("failed to create entity types client: %v", err)
+			}
+			defer func() {
+				if err = entityTypesClient.Close(); err != nil {
+					log.Printf("failed to close entity types client: %v", err)
+				}
+			}()
+
+			var (
+				entityTypesApplier dialogflow.EntityTypesApplier
+				intentsApplier     dialogflow.IntentsApplier
+			)
+			// Both files are checked before anything is applied, so that an
+			// error in the intents does not leave the entities applied.
+			if applyEntitiesFilename != "" {
+				source := dialogflow.NewFileSource(applyEntitiesFilename)
+				if err = dialogflow.ValidateEntityTypes(ctx, source, limits); err != nil {
+					log.Fatal(err)
+				}
+				entityTypesApplier = dialogflow.NewEntityTypesApplier(entityTypesClient, source)
+			}
+			if applyIntentsFilename != "" {
+				source := dialogflow.NewFileSource(applyIntentsFilename)
+				if err = dialogflow.ValidateIntents(ctx, source, limits); err != nil {
+					log.Fatal(err)
+				}
+				intentsApplier = dialogflow.NewIntentsApplier(intentsClient, source)
+			}
+
+			// Entity types are applied before and pruned after the intents,
+			// because intents may refer to them.
+			if entityTypesApplier != nil {
+				if err = entityTypesApplier.ApplyEntityTypes(ctx); err != nil {
+					log.Fatal(err)
+				}
+			}
+			if intentsApplier != nil {
+				if err = intentsApplier.ApplyIntents(ctx); err != nil {
+					log.Fatal(err)
+				}
+			}
+
+			if !applyPrune {
+				return
+			}
+
+			if intentsApplier != nil {
+				if err = intentsApplier.PruneIntents(ctx); err != nil {
+					log.Fatal(err)
+				}
+			}
+			if entityTypesApplier != nil {
+				if err = entityTypesApplier.PruneEntityTypes(ctx); err != nil {
+					log.Fatal(err)
+				}
+			}
+		},
+	}
+)
+
+func init() {
+	applyCmd.Flags().StringVarP(&applyIntentsFilename, "intents", "i", "intents.yaml", "intents filename, empty to skip intents")
+	applyCmd.Flags().StringVarP(&applyEntitiesFilename, "entities", "e", "entities.yaml", "entities filename, empty to skip entities")
+	applyCmd.Flags().BoolVar(&applyPrune, "prune", false, "delete intents and entities that are not in the given files")
+	addLimitsFlags(applyCmd.Flags())
+}
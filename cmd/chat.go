@@ -0,0 +1,389 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	chatSessionID    string
+	chatLanguageCode string
+
+	chatCmd = &cobra.Command{
+		Use:   "chat",
+		Short: "Chat with the agent, one line per message",
+		Long: `Chat with the agent, one line per message. After every message the matched
+intent, its confidence, the parameters, the active output contexts and the
+fulfillment text are shown.
+
+Commands:
+  /reset                          start a new session
+  /language <code>                switch the language
+  /contexts [name[:lifespan]...]  set the contexts of the next message, or
+                                  clear them without names
+  /reset-contexts                 delete the contexts of the session before
+                                  the next message
+  /entities <type>[:supplement] [value[=synonym,...]...]
+                                  override or supplement an entity type for
+                                  the session from the next message
+  /payload [json]                 set the webhook payload, or clear it
+  /timezone [zone]                set the time zone, or clear it
+  /location [latitude,longitude]  set the geo-location, or clear it
+  /sentiment on|off               analyze the sentiment of messages
+  /help                           show the commands
+  /quit                           stop chatting`,
+		Run: func(cmd *cobra.Command, _ []string) {
+			opts, err := detectIntentOptions()
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			ctx, cancel := newContext()
+			defer cancel()
+
+			sessionsClient, err := dialogflow.NewSessionsClient(ctx, projectID, clientOptions()...)
+			if err != nil {
+				log.Fatalf("failed to create sessions client: %v", err)
+			}
+			defer func() {
+				if err = sessionsClient.Close(); err != nil {
+					log.Printf("failed to close sessions client: %v", err)
+				}
+			}()
+
+			c := newChat(sessionsClient, chatSessionID, chatLanguageCode, opts)
+			if err = c.run(ctx, os.Stdin, cmd.OutOrStdout()); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
+
+func init() {
+	chatCmd.Flags().StringVarP(&chatSessionID, "session-id", "s", "", "session ID, defaults to a random ID")
+	chatCmd.Flags().StringVarP(&chatLanguageCode, "language", "l", "en", "language code")
+	addDetectIntentFlags(chatCmd.Flags())
+}
+
+// defaultChatContextLifespan is the lifespan of contexts set without a
+// lifespan.
+const defaultChatContextLifespan = 5
+
+type chat struct {
+	sessionsClient dialogflow.SessionsAPI
+	sessionID      string
+	languageCode   string
+
+	// opts are sent with every message.
+	opts dialogflow.DetectIntentOptions
+	// next holds the contexts and session entity types sent with the next
+	// message only.
+	next dialogflow.DetectIntentOptions
+}
+
+func newChat(sessionsClient dialogflow.SessionsAPI, sessionID, languageCode string, opts dialogflow.DetectIntentOptions) *chat {
+	if sessionID == "" {
+		sessionID = newSessionID()
+	}
+	return &chat{
+		sessionsClient: sessionsClient,
+		sessionID:      sessionID,
+		languageCode:   languageCode,
+		opts:           opts,
+	}
+}
+
+// newSessionID returns a random session ID.
+func newSessionID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("failed to create session id: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// run reads messages and commands from r, one per line, until r ends, the
+// quit command or ctx is done.
+func (c *chat) run(ctx context.Context, r io.Reader, w io.Writer) error {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	fmt.Fprintf(w, "session %s, language %s, /help for commands\n", c.sessionID, c.languageCode)
+	for {
+		fmt.Fprint(w, "> ")
+
+		var (
+			line string
+			ok   bool
+		)
+		select {
+		case line, ok = <-lines:
+		case <-ctx.Done():
+			fmt.Fprintln(w)
+			return nil
+		}
+		if !ok {
+			fmt.Fprintln(w)
+			return nil
+		}
+
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "/"):
+			quit, err := c.command(w, line)
+			if err != nil {
+				fmt.Fprintf(w, "error: %v\n", err)
+			}
+			if quit {
+				return nil
+			}
+		default:
+			if err := c.send(ctx, w, line); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Fprintf(w, "error: %v\n", err)
+			}
+		}
+	}
+}
+
+// command runs a command line and reports whether the chat should stop.
+func (c *chat) command(w io.Writer, line string) (bool, error) {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "/quit", "/exit":
+		return true, nil
+	case "/help":
+		fmt.Fprintln(w, "/reset, /language <code>, /contexts [name[:lifespan]...], /reset-contexts, "+
+			"/entities <type>[:supplement] [value[=synonym,...]...], /payload [json], /timezone [zone], "+
+			"/location [latitude,longitude], /sentiment on|off, /quit")
+	case "/reset":
+		c.sessionID = newSessionID()
+		c.next = dialogflow.DetectIntentOptions{}
+		fmt.Fprintf(w, "session %s\n", c.sessionID)
+	case "/language", "/lang":
+		if len(fields) != 2 {
+			return false, fmt.Errorf("usage: /language <code>")
+		}
+		c.languageCode = fields[1]
+		fmt.Fprintf(w, "language %s\n", c.languageCode)
+	case "/contexts", "/context":
+		contexts, err := parseContexts(fields[1:])
+		if err != nil {
+			return false, err
+		}
+		c.next.Contexts = contexts
+		if len(contexts) == 0 {
+			fmt.Fprintln(w, "contexts cleared")
+			break
+		}
+		fmt.Fprintf(w, "contexts %s for the next message\n", formatContexts(contexts))
+	case "/reset-contexts":
+		c.next.ResetContexts = true
+		fmt.Fprintln(w, "contexts reset before the next message")
+	case "/entities", "/entity":
+		if len(fields) < 2 {
+			return false, fmt.Errorf("usage: /entities <type>[:supplement] [value[=synonym,...]...]")
+		}
+		sessionEntityType, err := parseSessionEntityType(fields[1], fields[2:])
+		if err != nil {
+			return false, err
+		}
+		c.next.SessionEntityTypes = append(c.next.SessionEntityTypes, sessionEntityType)
+		fmt.Fprintf(w, "entity type %s with %d values from the next message\n", sessionEntityType.Name, len(sessionEntityType.Entities))
+	case "/payload":
+		payload, err := parsePayload(strings.TrimSpace(strings.TrimPrefix(line, fields[0])))
+		if err != nil {
+			return false, err
+		}
+		c.opts.Payload = payload
+		if payload == nil {
+			fmt.Fprintln(w, "payload cleared")
+			break
+		}
+		fmt.Fprintln(w, "payload set")
+	case "/timezone", "/time-zone":
+		if len(fields) > 2 {
+			return false, fmt.Errorf("usage: /timezone [zone]")
+		}
+		c.opts.TimeZone = strings.Join(fields[1:], "")
+		if c.opts.TimeZone == "" {
+			fmt.Fprintln(w, "time zone cleared")
+			break
+		}
+		fmt.Fprintf(w, "time zone %s\n", c.opts.TimeZone)
+	case "/location":
+		geoLocation, err := parseGeoLocation(strings.Join(fields[1:], ""))
+		if err != nil {
+			return false, err
+		}
+		c.opts.GeoLocation = geoLocation
+		if geoLocation == nil {
+			fmt.Fprintln(w, "location cleared")
+			break
+		}
+		fmt.Fprintf(w, "location %g,%g\n", geoLocation.Latitude, geoLocation.Longitude)
+	case "/sentiment":
+		if len(fields) != 2 || (fields[1] != "on" && fields[1] != "off") {
+			return false, fmt.Errorf("usage: /sentiment on|off")
+		}
+		c.opts.AnalyzeSentiment = fields[1] == "on"
+		fmt.Fprintf(w, "sentiment %s\n", fields[1])
+	default:
+		return false, fmt.Errorf("unknown command %s, /help for commands", fields[0])
+	}
+	return false, nil
+}
+
+// parseContexts parses contexts written as name or name:lifespan.
+func parseContexts(args []string) ([]dialogflow.Context, error) {
+	var contexts []dialogflow.Context
+	for _, arg := range args {
+		name, lifespan := arg, int32(defaultChatContextLifespan)
+		if i := strings.LastIndex(arg, ":"); i >= 0 {
+			n, err := strconv.ParseInt(arg[i+1:], 10, 32)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid lifespan in context %q", arg)
+			}
+			name, lifespan = arg[:i], int32(n)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("context name is empty")
+		}
+		contexts = append(contexts, dialogflow.Context{
+			Name:          name,
+			LifespanCount: lifespan,
+		})
+	}
+	return contexts, nil
+}
+
+// parseSessionEntityType parses an entity type written as type or
+// type:supplement, with values written as value or value=synonym,....
+func parseSessionEntityType(arg string, values []string) (dialogflow.SessionEntityType, error) {
+	sessionEntityType := dialogflow.SessionEntityType{
+		Name:               strings.TrimPrefix(arg, "@"),
+		EntityOverrideMode: "ENTITY_OVERRIDE_MODE_OVERRIDE",
+	}
+	if i := strings.LastIndex(arg, ":"); i >= 0 {
+		switch arg[i+1:] {
+		case "override":
+		case "supplement":
+			sessionEntityType.EntityOverrideMode = "ENTITY_OVERRIDE_MODE_SUPPLEMENT"
+		default:
+			return dialogflow.SessionEntityType{}, fmt.Errorf("invalid mode in entity type %q, must be override or supplement", arg)
+		}
+		sessionEntityType.Name = strings.TrimPrefix(arg[:i], "@")
+	}
+	if sessionEntityType.Name == "" {
+		return dialogflow.SessionEntityType{}, fmt.Errorf("entity type name is empty")
+	}
+
+	for _, value := range values {
+		entity := dialogflow.Entity{Value: value}
+		if i := strings.Index(value, "="); i >= 0 {
+			entity.Value = value[:i]
+			entity.Synonyms = strings.Split(value[i+1:], ",")
+		}
+		if entity.Value == "" {
+			return dialogflow.SessionEntityType{}, fmt.Errorf("entity value is empty in %q", value)
+		}
+		// Dialogflow only matches synonyms, so the value is one too.
+		if !containsString(entity.Synonyms, entity.Value) {
+			entity.Synonyms = append([]string{entity.Value}, entity.Synonyms...)
+		}
+		sessionEntityType.Entities = append(sessionEntityType.Entities, entity)
+	}
+	return sessionEntityType, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *chat) send(ctx context.Context, w io.Writer, text string) error {
+	opts := c.opts
+	opts.Contexts = c.next.Contexts
+	opts.ResetContexts = c.next.ResetContexts
+	opts.SessionEntityTypes = c.next.SessionEntityTypes
+
+	result, err := c.sessionsClient.DetectIntentText(ctx, c.sessionID, text, c.languageCode, opts)
+	if err != nil {
+		return err
+	}
+	c.next = dialogflow.DetectIntentOptions{}
+
+	printDetectIntentResult(w, result)
+	return nil
+}
+
+func printDetectIntentResult(w io.Writer, result dialogflow.DetectIntentResult) {
+	if result.Intent != nil {
+		fmt.Fprintf(w, "  intent:      %s (confidence %.2f)\n", result.Intent.DisplayName, result.IntentDetectionConfidence)
+	} else {
+		fmt.Fprintln(w, "  intent:      none")
+	}
+
+	if len(result.Parameters) > 0 {
+		parameters, err := json.Marshal(result.Parameters)
+		if err != nil {
+			parameters = []byte(fmt.Sprint(result.Parameters))
+		}
+		fmt.Fprintf(w, "  parameters:  %s\n", parameters)
+	}
+
+	var active []dialogflow.Context
+	for _, outputContext := range result.OutputContexts {
+		if outputContext.LifespanCount > 0 {
+			active = append(active, outputContext)
+		}
+	}
+	if len(active) > 0 {
+		fmt.Fprintf(w, "  contexts:    %s\n", formatContexts(active))
+	}
+
+	if sentiment := result.QueryTextSentiment; sentiment != nil {
+		fmt.Fprintf(w, "  sentiment:   %.2f (magnitude %.2f)\n", sentiment.Score, sentiment.Magnitude)
+	}
+
+	fmt.Fprintf(w, "  fulfillment: %s\n", result.FulfillmentText)
+}
+
+func formatContexts(contexts []dialogflow.Context) string {
+	var values []string
+	for _, c := range contexts {
+		values = append(values, fmt.Sprintf("%s (%d)", path.Base(c.Name), c.LifespanCount))
+	}
+	return strings.Join(values, ", ")
+}
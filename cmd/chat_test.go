@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow/dialogflowtest"
+)
+
+func TestChat(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient, err := dialogflow.NewIntentsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer intentsClient.Close()
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err = importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionsClient, err := dialogflow.NewSessionsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sessionsClient.Close()
+
+	input := strings.Join([]string{
+		"Hi, my name is John",
+		"How are you?",
+		"/contexts order:2",
+		"What time is it?",
+		"/language de",
+		"/unknown",
+		"/quit",
+		"How are you?",
+	}, "\n")
+
+	var output bytes.Buffer
+	c := newChat(sessionsClient, "session", "en", dialogflow.DetectIntentOptions{})
+	if err = c.run(context.Background(), strings.NewReader(input), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `session session, language en, /help for commands
+>   intent:      My name is @name (confidence 1.00)
+  parameters:  {"name":"John"}
+  fulfillment: Hi $name, how are you doing?
+>   intent:      How are you? (confidence 1.00)
+  contexts:    how-are-you (2)
+  fulfillment: I'm great, thanks.
+> contexts order (2) for the next message
+>   intent:      none
+  contexts:    order (2)
+  fulfillment: 
+> language de
+> error: unknown command /unknown, /help for commands
+> `
+
+	if output.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, output.String())
+	}
+}
+
+func TestChatOptions(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	sessionsClient, err := dialogflow.NewSessionsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sessionsClient.Close()
+
+	input := strings.Join([]string{
+		"/timezone Europe/Amsterdam",
+		"/location 52.37,4.89",
+		`/payload {"source": "chat"}`,
+		"/sentiment on",
+		"/reset-contexts",
+		"/entities size:supplement xl=xl,extra-large small",
+		"Hello",
+		"/payload",
+		"/entities size:merge",
+		"Hello",
+	}, "\n")
+
+	var output bytes.Buffer
+	c := newChat(sessionsClient, "session", "en", dialogflow.DetectIntentOptions{})
+	if err = c.run(context.Background(), strings.NewReader(input), &output); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `session session, language en, /help for commands
+> time zone Europe/Amsterdam
+> location 52.37,4.89
+> payload set
+> sentiment on
+> contexts reset before the next message
+> entity type size with 2 values from the next message
+>   intent:      none
+  sentiment:   0.00 (magnitude 0.00)
+  fulfillment: 
+> payload cleared
+> error: invalid mode in entity type "size:merge", must be override or supplement
+>   intent:      none
+  sentiment:   0.00 (magnitude 0.00)
+  fulfillment: 
+> 
+`
+
+	if output.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, output.String())
+	}
+
+	requests := server.DetectIntentRequests()
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(requests))
+	}
+
+	first := requests[0].QueryParams
+	if first.TimeZone != "Europe/Amsterdam" || first.GetGeoLocation().GetLatitude() != 52.37 || !first.ResetContexts {
+		t.Errorf("expected time zone, geo-location and reset contexts, got %v", first)
+	}
+	if first.GetPayload().GetFields()["source"].GetStringValue() != "chat" {
+		t.Errorf("expected payload, got %v", first.GetPayload())
+	}
+	if len(first.SessionEntityTypes) != 1 || first.SessionEntityTypes[0].EntityOverrideMode.String() != "ENTITY_OVERRIDE_MODE_SUPPLEMENT" {
+		t.Errorf("expected a supplementing session entity type, got %v", first.SessionEntityTypes)
+	}
+
+	second := requests[1].QueryParams
+	if second.Payload != nil || second.ResetContexts || len(second.SessionEntityTypes) > 0 {
+		t.Errorf("expected no payload, context reset or session entity types, got %v", second)
+	}
+	if second.TimeZone != "Europe/Amsterdam" {
+		t.Errorf("expected time zone for every message, got %q", second.TimeZone)
+	}
+}
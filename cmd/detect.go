@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	detectSessionID    string
+	detectLanguageCode string
+	detectContexts     []string
+	detectTimeZone     string
+	detectGeoLocation  string
+	detectPayload      string
+	detectSentiment    bool
+
+	detectCmd = &cobra.Command{
+		Use:   "detect",
+		Short: "Detect the intent of a single query",
+	}
+)
+
+func init() {
+	detectCmd.PersistentFlags().StringVarP(&detectSessionID, "session-id", "s", "", "session ID, defaults to a random ID")
+	detectCmd.PersistentFlags().StringVarP(&detectLanguageCode, "language", "l", "en", "language code")
+	detectCmd.PersistentFlags().StringSliceVar(&detectContexts, "context", nil, "context to activate before the query as name[:lifespan], can be repeated")
+	addDetectIntentFlags(detectCmd.PersistentFlags())
+	detectCmd.AddCommand(detectAudioCmd)
+	detectCmd.AddCommand(detectEventCmd)
+	detectCmd.AddCommand(detectStreamCmd)
+}
+
+// addDetectIntentFlags adds the flags for the query parameters that are the
+// same for every query.
+func addDetectIntentFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&detectTimeZone, "time-zone", "", "time zone of the queries, such as Europe/Paris, defaults to the time zone of the agent")
+	flags.StringVar(&detectGeoLocation, "geo-location", "", "geo-location of the user as latitude,longitude")
+	flags.StringVar(&detectPayload, "payload", "", "JSON object passed to the webhook with every query")
+	flags.BoolVar(&detectSentiment, "sentiment", false, "analyze the sentiment of the queries")
+}
+
+// detectIntentOptions returns the options of the detect intent flags.
+func detectIntentOptions() (dialogflow.DetectIntentOptions, error) {
+	opts := dialogflow.DetectIntentOptions{
+		TimeZone:         detectTimeZone,
+		AnalyzeSentiment: detectSentiment,
+	}
+	var err error
+	if opts.GeoLocation, err = parseGeoLocation(detectGeoLocation); err != nil {
+		return dialogflow.DetectIntentOptions{}, err
+	}
+	if opts.Payload, err = parsePayload(detectPayload); err != nil {
+		return dialogflow.DetectIntentOptions{}, err
+	}
+	return opts, nil
+}
+
+// runDetect detects an intent with a new sessions client and prints the
+// result.
+func runDetect(w io.Writer, detect func(ctx context.Context, sessionsClient dialogflow.SessionsAPI, sessionID string, opts dialogflow.DetectIntentOptions) (dialogflow.DetectIntentResult, error)) {
+	opts, err := detectIntentOptions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if opts.Contexts, err = parseContexts(detectContexts); err != nil {
+		log.Fatal(err)
+	}
+
+	sessionID := detectSessionID
+	if sessionID == "" {
+		sessionID = newSessionID()
+	}
+
+	ctx, cancel := newContext()
+	defer cancel()
+
+	sessionsClient, err := dialogflow.NewSessionsClient(ctx, projectID, clientOptions()...)
+	if err != nil {
+		log.Fatalf("failed to create sessions client: %v", err)
+	}
+	defer func() {
+		if err = sessionsClient.Close(); err != nil {
+			log.Printf("failed to close sessions client: %v", err)
+		}
+	}()
+
+	result, err := detect(ctx, sessionsClient, sessionID, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Fprintf(w, "  query:       %s\n", result.QueryText)
+	printDetectIntentResult(w, result)
+}
+
+// parseGeoLocation parses a geo-location written as latitude,longitude, or
+// returns nil for an empty string.
+func parseGeoLocation(s string) (*dialogflow.GeoLocation, error) {
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid geo-location %q, must be latitude,longitude", s)
+	}
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return nil, fmt.Errorf("invalid latitude in geo-location %q", s)
+	}
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return nil, fmt.Errorf("invalid longitude in geo-location %q", s)
+	}
+	return &dialogflow.GeoLocation{Latitude: latitude, Longitude: longitude}, nil
+}
+
+// parsePayload parses a payload written as a JSON object, or returns nil for
+// an empty string.
+func parsePayload(s string) (map[string]interface{}, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &payload); err != nil || payload == nil {
+		return nil, fmt.Errorf("invalid payload, must be a JSON object: %s", s)
+	}
+	return payload, nil
+}
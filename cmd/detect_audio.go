@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	detectAudioFilename       string
+	detectAudioOutputFilename string
+
+	detectAudioCmd = &cobra.Command{
+		Use:   "audio",
+		Short: "Detect the intent of the speech in a WAV, FLAC or OGG Opus file",
+		Run: func(cmd *cobra.Command, _ []string) {
+			audio := dialogflow.AudioFile{
+				Filename:       detectAudioFilename,
+				LanguageCode:   detectLanguageCode,
+				OutputFilename: detectAudioOutputFilename,
+			}
+			runDetect(cmd.OutOrStdout(), func(ctx context.Context, sessionsClient dialogflow.SessionsAPI, sessionID string, opts dialogflow.DetectIntentOptions) (dialogflow.DetectIntentResult, error) {
+				return sessionsClient.DetectIntentAudio(ctx, sessionID, audio, opts)
+			})
+		},
+	}
+)
+
+func init() {
+	detectAudioCmd.Flags().StringVarP(&detectAudioFilename, "file", "f", "", "mono WAV, FLAC or OGG Opus file with the speech")
+	detectAudioCmd.Flags().StringVarP(&detectAudioOutputFilename, "output-audio", "o", "", "file to save the spoken response to, as .wav, .mp3 or .ogg")
+	_ = detectAudioCmd.MarkFlagRequired("file")
+}
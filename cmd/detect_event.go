@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/spf13/cobra"
+)
+
+var detectEventCmd = &cobra.Command{
+	Use:   "event <name> [parameter=value...]",
+	Short: "Trigger the intents of an event, with parameters",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		params, err := parseEventParameters(args[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		runDetect(cmd.OutOrStdout(), func(ctx context.Context, sessionsClient dialogflow.SessionsAPI, sessionID string, opts dialogflow.DetectIntentOptions) (dialogflow.DetectIntentResult, error) {
+			return sessionsClient.DetectIntentEvent(ctx, sessionID, args[0], params, detectLanguageCode, opts)
+		})
+	},
+}
+
+// parseEventParameters parses parameters written as name=value, or returns
+// nil without parameters.
+func parseEventParameters(args []string) (map[string]interface{}, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	params := make(map[string]interface{})
+	for _, arg := range args {
+		i := strings.Index(arg, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid parameter %q, must be name=value", arg)
+		}
+		params[arg[:i]] = arg[i+1:]
+	}
+	return params, nil
+}
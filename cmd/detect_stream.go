@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	detectStreamFilename string
+
+	detectStreamCmd = &cobra.Command{
+		Use:   "stream",
+		Short: "Stream the speech in a WAV, FLAC or OGG Opus file, showing what is recognized",
+		Run: func(cmd *cobra.Command, _ []string) {
+			runDetect(cmd.OutOrStdout(), func(ctx context.Context, sessionsClient dialogflow.SessionsAPI, sessionID string, opts dialogflow.DetectIntentOptions) (dialogflow.DetectIntentResult, error) {
+				file, err := os.Open(detectStreamFilename)
+				if err != nil {
+					return dialogflow.DetectIntentResult{}, fmt.Errorf("open audio file: %v", err)
+				}
+				defer func() {
+					if err = file.Close(); err != nil {
+						log.Printf("failed to close file: %v", err)
+					}
+				}()
+
+				return streamDetectIntent(ctx, cmd.OutOrStdout(), sessionsClient, sessionID, file, opts)
+			})
+		},
+	}
+)
+
+func init() {
+	detectStreamCmd.Flags().StringVarP(&detectStreamFilename, "file", "f", "", "mono WAV, FLAC or OGG Opus file with the speech")
+	_ = detectStreamCmd.MarkFlagRequired("file")
+}
+
+// streamDetectIntent streams the audio and prints the recognition results as
+// they arrive.
+func streamDetectIntent(ctx context.Context, w io.Writer, sessionsClient dialogflow.SessionsAPI, sessionID string, audio io.Reader, opts dialogflow.DetectIntentOptions) (dialogflow.DetectIntentResult, error) {
+	recognitionResults := make(chan dialogflow.RecognitionResult)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for result := range recognitionResults {
+			printRecognitionResult(w, result)
+		}
+	}()
+
+	result, err := sessionsClient.StreamingDetectIntent(ctx, sessionID, audio, detectLanguageCode, opts, recognitionResults)
+	<-done
+	return result, err
+}
+
+func printRecognitionResult(w io.Writer, result dialogflow.RecognitionResult) {
+	switch {
+	case result.EndOfSingleUtterance:
+		fmt.Fprintln(w, "  recognized:  end of utterance")
+	case result.IsFinal:
+		fmt.Fprintf(w, "  recognized:  %s (confidence %.2f)\n", result.Transcript, result.Confidence)
+	default:
+		fmt.Fprintf(w, "  recognizing: %s\n", result.Transcript)
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow/dialogflowtest"
+)
+
+func TestStreamDetectIntent(t *testing.T) {
+	recorder := dialogflowtest.NewSessionsRecorder()
+	recorder.RecognitionResults["speech"] = []dialogflow.RecognitionResult{
+		{Transcript: "How are"},
+		{Transcript: "How are you?", IsFinal: true, Confidence: 0.9},
+		{EndOfSingleUtterance: true},
+	}
+	recorder.Results["speech"] = dialogflow.DetectIntentResult{QueryText: "How are you?", FulfillmentText: "I'm great, thanks."}
+
+	var output bytes.Buffer
+	result, err := streamDetectIntent(context.Background(), &output, recorder, "session", bytes.NewReader([]byte("speech")), dialogflow.DetectIntentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FulfillmentText != "I'm great, thanks." {
+		t.Errorf("expected the recorded result, got %+v", result)
+	}
+
+	expected := `  recognizing: How are
+  recognized:  How are you? (confidence 0.90)
+  recognized:  end of utterance
+`
+	if output.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, output.String())
+	}
+}
@@ -10,5 +10,6 @@ var entitiesCmd = &cobra.Command{
 
 func init() {
 	entitiesCmd.AddCommand(entitiesDeleteCmd)
+	entitiesCmd.AddCommand(entitiesExportCmd)
 	entitiesCmd.AddCommand(entitiesImportCmd)
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -15,7 +16,10 @@ var (
 	entitiesDeleteCmd = &cobra.Command{
 		Use: "delete",
 		Run: func(_ *cobra.Command, _ []string) {
-			entityTypesClient, err := dialogflow.NewEntityTypesClient(projectID, credentialsFile)
+			ctx, cancel := newContext()
+			defer cancel()
+
+			entityTypesClient, err := dialogflow.NewEntityTypesClient(ctx, projectID, clientOptions()...)
 			if err != nil {
 				log.Fatalf("failed to create entity types client: %v", err)
 			}
@@ -26,13 +30,13 @@ var (
 			}()
 
 			if entitiesDeleteAll {
-				if err := deleteAllEntityTypes(entityTypesClient); err != nil {
+				if err := deleteAllEntityTypes(ctx, entityTypesClient); err != nil {
 					log.Fatal(err)
 				}
 				return
 			}
 
-			if err = deleteEntityType(entityTypesClient, entitiesDeleteEntityTypeID); err != nil {
+			if err = deleteEntityType(ctx, entityTypesClient, entitiesDeleteEntityTypeID); err != nil {
 				log.Fatal(err)
 			}
 		},
@@ -44,21 +48,24 @@ func init() {
 	entitiesDeleteCmd.Flags().StringVarP(&entitiesDeleteEntityTypeID, "id", "i", "", "delete entities for the given entity type id")
 }
 
-func deleteEntityType(entityTypesClient *dialogflow.EntityTypesClient, entityTypeID string) error {
-	if err := entityTypesClient.DeleteEntityType(entityTypeID); err != nil {
+func deleteEntityType(ctx context.Context, entityTypesClient dialogflow.EntityTypesAPI, entityTypeID string) error {
+	if err := entityTypesClient.DeleteEntityType(ctx, entityTypeID); err != nil {
 		return fmt.Errorf("delete entity type: %v", err)
 	}
 	return nil
 }
 
-func deleteAllEntityTypes(entityTypesClient *dialogflow.EntityTypesClient) error {
-	entityTypes, err := entityTypesClient.ListEntityTypes()
+func deleteAllEntityTypes(ctx context.Context, entityTypesClient dialogflow.EntityTypesAPI) error {
+	entityTypes, err := entityTypesClient.ListEntityTypes(ctx, "")
 	if err != nil {
 		return fmt.Errorf("list entity types: %v", err)
 	}
-	err = entityTypesClient.DeleteEntityTypes(entityTypes)
+	op, err := entityTypesClient.DeleteEntityTypes(ctx, entityTypes)
 	if err != nil {
 		return fmt.Errorf("delete entity types: %v", err)
 	}
+	if err = waitOperation(ctx, op); err != nil {
+		return fmt.Errorf("wait for delete entity types: %v", err)
+	}
 	return nil
 }
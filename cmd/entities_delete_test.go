@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow/dialogflowtest"
+)
+
+func TestDeleteAllEntityTypes(t *testing.T) {
+	entityTypesRecorder := dialogflowtest.NewEntityTypesRecorder()
+	entityTypesRecorder.EntityTypes = []dialogflow.EntityType{
+		{Name: "projects/example/agent/entityTypes/1", DisplayName: "fruit"},
+		{Name: "projects/example/agent/entityTypes/2", DisplayName: "color"},
+	}
+
+	if err := deleteAllEntityTypes(context.Background(), entityTypesRecorder); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"ListEntityTypes", "DeleteEntityTypes"}
+	if methods := entityTypesRecorder.Methods(); !reflect.DeepEqual(expected, methods) {
+		t.Errorf("expected %v, got %v", expected, methods)
+	}
+
+	if len(entityTypesRecorder.EntityTypes) != 0 {
+		t.Errorf("expected no entity types, got %v", entityTypesRecorder.EntityTypes)
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	entitiesExportFilename  string
+	entitiesExportLanguages []string
+
+	entitiesExportCmd = &cobra.Command{
+		Use: "export",
+		Run: func(cmd *cobra.Command, _ []string) {
+			ctx, cancel := newContext()
+			defer cancel()
+
+			entityTypesClient, err := dialogflow.NewEntityTypesClient(ctx, projectID, clientOptions()...)
+			if err != nil {
+				log.Fatalf("failed to create entity types client: %v", err)
+			}
+			defer func() {
+				if err = entityTypesClient.Close(); err != nil {
+					log.Printf("failed to close entity types client: %v", err)
+				}
+			}()
+
+			writer := cmd.OutOrStdout()
+			if entitiesExportFilename != "" {
+				file, err := os.Create(entitiesExportFilename)
+				if err != nil {
+					log.Fatalf("failed to create file: %v", err)
+				}
+				defer func() {
+					if err = file.Close(); err != nil {
+						log.Printf("failed to close file: %v", err)
+					}
+				}()
+				writer = file
+			}
+
+			exporter := dialogflow.NewEntityTypesExporter(entityTypesClient, writer, entitiesExportLanguages...)
+			if err = exporter.ExportEntityTypes(ctx); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
+
+func init() {
+	entitiesExportCmd.Flags().StringVarP(&entitiesExportFilename, "filename", "f", "", "entities filename, defaults to stdout")
+	entitiesExportCmd.Flags().StringSliceVar(&entitiesExportLanguages, "language", nil, "language codes of the translations to export")
+}
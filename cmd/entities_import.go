@@ -8,13 +8,17 @@ import (
 )
 
 var (
-	entitiesImportFilename string
-	entitiesImportURL      string
+	entitiesImportFilename  string
+	entitiesImportURL       string
+	entitiesImportLanguages []string
 
 	entitiesImportCmd = &cobra.Command{
 		Use: "import",
 		Run: func(_ *cobra.Command, _ []string) {
-			entityTypesClient, err := dialogflow.NewEntityTypesClient(projectID, credentialsFile)
+			ctx, cancel := newContext()
+			defer cancel()
+
+			entityTypesClient, err := dialogflow.NewEntityTypesClient(ctx, projectID, clientOptions()...)
 			if err != nil {
 				log.Fatalf("failed to create entity types client: %v", err)
 			}
@@ -31,8 +35,8 @@ var (
 				source = dialogflow.NewFileSource(entitiesImportFilename)
 			}
 
-			importer := dialogflow.NewEntityTypesImporter(entityTypesClient, source)
-			if err = importer.ImportEntityTypes(); err != nil {
+			importer := dialogflow.NewEntityTypesImporter(entityTypesClient, source, dialogflow.WithLanguages(entitiesImportLanguages...), dialogflow.WithLimits(limits))
+			if err = importer.ImportEntityTypes(ctx); err != nil {
 				log.Fatal(err)
 			}
 		},
@@ -42,4 +46,6 @@ var (
 func init() {
 	entitiesImportCmd.Flags().StringVarP(&entitiesImportFilename, "filename", "f", "entities.yaml", "entities filename")
 	entitiesImportCmd.Flags().StringVarP(&entitiesImportURL, "url", "u", "", "entities url")
+	entitiesImportCmd.Flags().StringSliceVar(&entitiesImportLanguages, "language", nil, "language codes of the translations to import, defaults to all")
+	addLimitsFlags(entitiesImportCmd.Flags())
 }
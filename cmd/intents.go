@@ -10,5 +10,6 @@ var intentsCmd = &cobra.Command{
 
 func init() {
 	intentsCmd.AddCommand(intentsDeleteCmd)
+	intentsCmd.AddCommand(intentsExportCmd)
 	intentsCmd.AddCommand(intentsImportCmd)
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -15,7 +16,10 @@ var (
 	intentsDeleteCmd = &cobra.Command{
 		Use: "delete",
 		Run: func(_ *cobra.Command, _ []string) {
-			intentsClient, err := dialogflow.NewIntentsClient(projectID, credentialsFile)
+			ctx, cancel := newContext()
+			defer cancel()
+
+			intentsClient, err := dialogflow.NewIntentsClient(ctx, projectID, clientOptions()...)
 			if err != nil {
 				log.Fatalf("failed to create intents client: %v", err)
 			}
@@ -26,13 +30,13 @@ var (
 			}()
 
 			if intentsDeleteAll {
-				if err := deleteAllIntents(intentsClient); err != nil {
+				if err := deleteAllIntents(ctx, intentsClient); err != nil {
 					log.Fatal(err)
 				}
 				return
 			}
 
-			if err = deleteIntent(intentsClient, intentsDeleteIntentID); err != nil {
+			if err = deleteIntent(ctx, intentsClient, intentsDeleteIntentID); err != nil {
 				log.Fatal(err)
 			}
 		},
@@ -44,15 +48,15 @@ func init() {
 	intentsDeleteCmd.Flags().StringVarP(&intentsDeleteIntentID, "id", "i", "", "delete intent for the given intent id")
 }
 
-func deleteIntent(intentsClient *dialogflow.IntentsClient, intentID string) error {
-	if err := intentsClient.DeleteIntent(intentID); err != nil {
+func deleteIntent(ctx context.Context, intentsClient dialogflow.IntentsAPI, intentID string) error {
+	if err := intentsClient.DeleteIntent(ctx, intentID); err != nil {
 		return fmt.Errorf("delete intent: %v", err)
 	}
 	return nil
 }
 
-func deleteAllIntents(intentsClient *dialogflow.IntentsClient) error {
-	intents, err := intentsClient.ListIntents()
+func deleteAllIntents(ctx context.Context, intentsClient dialogflow.IntentsAPI) error {
+	intents, err := intentsClient.ListIntents(ctx, dialogflow.IntentViewUnspecified, "")
 	if err != nil {
 		return fmt.Errorf("list intents: %v", err)
 	}
@@ -60,9 +64,12 @@ func deleteAllIntents(intentsClient *dialogflow.IntentsClient) error {
 	for _, intent := range intents {
 		deleteIntents = append(deleteIntents, dialogflow.Intent{Name: intent.Name})
 	}
-	err = intentsClient.DeleteIntents(deleteIntents)
+	op, err := intentsClient.DeleteIntents(ctx, deleteIntents)
 	if err != nil {
 		return fmt.Errorf("delete intents: %v", err)
 	}
+	if err = waitOperation(ctx, op); err != nil {
+		return fmt.Errorf("wait for delete intents: %v", err)
+	}
 	return nil
 }
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow/dialogflowtest"
+)
+
+func TestDeleteAllIntents(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient, err := dialogflow.NewIntentsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer intentsClient.Close()
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err = importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = deleteAllIntents(context.Background(), intentsClient); err != nil {
+		t.Fatal(err)
+	}
+
+	if intents := server.Intents(); len(intents) != 0 {
+		t.Errorf("expected no intents, got %v", intents)
+	}
+}
+
+func TestDeleteAllIntentsOperationError(t *testing.T) {
+	defer func(interval time.Duration) {
+		pollInterval = interval
+	}(pollInterval)
+	pollInterval = time.Millisecond
+
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient, err := dialogflow.NewIntentsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer intentsClient.Close()
+
+	if _, err = intentsClient.CreateIntent(context.Background(), dialogflow.Intent{DisplayName: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.SetOperationResult(1, errors.New("deletion failed"))
+
+	if err = deleteAllIntents(context.Background(), intentsClient); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	intentsExportFilename  string
+	intentsExportLanguages []string
+
+	intentsExportCmd = &cobra.Command{
+		Use: "export",
+		Run: func(cmd *cobra.Command, _ []string) {
+			ctx, cancel := newContext()
+			defer cancel()
+
+			intentsClient, err := dialogflow.NewIntentsClient(ctx, projectID, clientOptions()...)
+			if err != nil {
+				log.Fatalf("failed to create intents client: %v", err)
+			}
+			defer func() {
+				if err = intentsClient.Close(); err != nil {
+					log.Printf("failed to close intents client: %v", err)
+				}
+			}()
+
+			writer := cmd.OutOrStdout()
+			if intentsExportFilename != "" {
+				file, err := os.Create(intentsExportFilename)
+				if err != nil {
+					log.Fatalf("failed to create file: %v", err)
+				}
+				defer func() {
+					if err = file.Close(); err != nil {
+						log.Printf("failed to close file: %v", err)
+					}
+				}()
+				writer = file
+			}
+
+			exporter := dialogflow.NewIntentsExporter(intentsClient, writer, intentsExportLanguages...)
+			if err = exporter.ExportIntents(ctx); err != nil {
+				log.Fatal(err)
+			}
+		},
+	}
+)
+
+func init() {
+	intentsExportCmd.Flags().StringVarP(&intentsExportFilename, "filename", "f", "", "intents filename, defaults to stdout")
+	intentsExportCmd.Flags().StringSliceVar(&intentsExportLanguages, "language", nil, "language codes of the translations to export")
+}
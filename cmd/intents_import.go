@@ -8,13 +8,17 @@ import (
 )
 
 var (
-	intentsImportFilename string
-	intentsImportURL      string
+	intentsImportFilename  string
+	intentsImportURL       string
+	intentsImportLanguages []string
 
 	intentsImportCmd = &cobra.Command{
 		Use: "import",
 		Run: func(_ *cobra.Command, _ []string) {
-			intentsClient, err := dialogflow.NewIntentsClient(projectID, credentialsFile)
+			ctx, cancel := newContext()
+			defer cancel()
+
+			intentsClient, err := dialogflow.NewIntentsClient(ctx, projectID, clientOptions()...)
 			if err != nil {
 				log.Fatalf("failed to create intents client: %v", err)
 			}
@@ -31,8 +35,8 @@ var (
 				source = dialogflow.NewFileSource(intentsImportFilename)
 			}
 
-			importer := dialogflow.NewIntentsImporter(intentsClient, source)
-			if err = importer.ImportIntents(); err != nil {
+			importer := dialogflow.NewIntentsImporter(intentsClient, source, dialogflow.WithLanguages(intentsImportLanguages...), dialogflow.WithLimits(limits))
+			if err = importer.ImportIntents(ctx); err != nil {
 				log.Fatal(err)
 			}
 		},
@@ -42,4 +46,6 @@ var (
 func init() {
 	intentsImportCmd.Flags().StringVarP(&intentsImportFilename, "filename", "f", "intents.yaml", "intents filename")
 	intentsImportCmd.Flags().StringVarP(&intentsImportURL, "url", "u", "", "intents url")
+	intentsImportCmd.Flags().StringSliceVar(&intentsImportLanguages, "language", nil, "language codes of the translations to import, defaults to all")
+	addLimitsFlags(intentsImportCmd.Flags())
 }
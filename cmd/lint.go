@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintIntentsFilename  string
+	lintEntitiesFilename string
+
+	lintCmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Check intents and entities for problems without calling the API",
+		Run: func(cmd *cobra.Command, _ []string) {
+			ctx, cancel := newContext()
+			defer cancel()
+
+			var intentsSource, entityTypesSource dialogflow.Source
+			if lintIntentsFilename != "" {
+				intentsSource = dialogflow.NewFileSource(lintIntentsFilename)
+			}
+			if lintEntitiesFilename != "" {
+				entityTypesSource = dialogflow.NewFileSource(lintEntitiesFilename)
+			}
+
+			problems, err := dialogflow.NewLinter(intentsSource, entityTypesSource, limits).Lint(ctx)
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			for _, problem := range problems {
+				fmt.Fprintln(cmd.OutOrStdout(), problem)
+			}
+			if len(problems) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	lintCmd.Flags().StringVarP(&lintIntentsFilename, "intents", "i", "intents.yaml", "intents filename, empty to skip intents")
+	lintCmd.Flags().StringVarP(&lintEntitiesFilename, "entities", "e", "entities.yaml", "entities filename, empty to skip entities")
+	addLimitsFlags(lintCmd.Flags())
+}
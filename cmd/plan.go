@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/spf13/cobra"
+)
+
+var (
+	planIntentsFilename  string
+	planEntitiesFilename string
+	planPrune            bool
+	planOutput           string
+
+	planCmd = &cobra.Command{
+		Use:   "plan",
+		Short: "Show the changes apply would make to intents and entities",
+		Run: func(cmd *cobra.Command, _ []string) {
+			ctx, cancel := newContext()
+			defer cancel()
+
+			if planOutput != "text" && planOutput != "json" {
+				log.Fatalf("unknown output format %q", planOutput)
+			}
+
+			intentsClient, err := dialogflow.NewIntentsClient(ctx, projectID, clientOptions()...)
+			if err != nil {
+				log.Fatalf("failed to create intents client: %v", err)
+			}
+			defer func() {
+				if err = intentsClient.Close(); err != nil {
+					log.Printf("failed to close intents client: %v", err)
+				}
+			}()
+
+			entityTypesClient, err := dialogflow.NewEntityTypesClient(ctx, projectID, clientOptions()...)
+			if err != nil {
+				log.Fatalf("failed to create entity types client: %v", err)
+			}
+			defer func() {
+				if err = entityTypesClient.Close(); err != nil {
+					log.Printf("failed to close entity types client: %v", err)
+				}
+			}()
+
+			plan := dialogflow.Plan{
+				EntityTypes: []dialogflow.EntityTypeChange{},
+				Intents:     []dialogflow.IntentChange{},
+			}
+			if planEntitiesFilename != "" {
+				applier := dialogflow.NewEntityTypesApplier(entityTypesClient, dialogflow.NewFileSource(planEntitiesFilename))
+				changes, err := applier.PlanEntityTypes(ctx)
+				if err != nil {
+					log.Fatal(err)
+				}
+				for _, change := range changes {
+					if planPrune || change.Action != dialogflow.ChangeActionRemove {
+						plan.EntityTypes = append(plan.EntityTypes, change)
+					}
+				}
+			}
+			if planIntentsFilename != "" {
+				applier := dialogflow.NewIntentsApplier(intentsClient, dialogflow.NewFileSource(planIntentsFilename))
+				changes, err := applier.PlanIntents(ctx)
+				if err != nil {
+					log.Fatal(err)
+				}
+				for _, change := range changes {
+					if planPrune || change.Action != dialogflow.ChangeActionRemove {
+						plan.Intents = append(plan.Intents, change)
+					}
+				}
+			}
+
+			if planOutput == "json" {
+				encoder := json.NewEncoder(cmd.OutOrStdout())
+				encoder.SetIndent("", "  ")
+				if err = encoder.Encode(plan); err != nil {
+					log.Fatal(err)
+				}
+				return
+			}
+
+			printPlan(cmd.OutOrStdout(), plan)
+		},
+	}
+)
+
+func init() {
+	planCmd.Flags().StringVarP(&planIntentsFilename, "intents", "i", "intents.yaml", "intents filename, empty to skip intents")
+	planCmd.Flags().StringVarP(&planEntitiesFilename, "entities", "e", "entities.yaml", "entities filename, empty to skip entities")
+	planCmd.Flags().BoolVar(&planPrune, "prune", false, "show intents and entities that are not in the given files as removed")
+	planCmd.Flags().StringVarP(&planOutput, "output", "o", "text", "output format, text or json")
+}
+
+var changeSymbols = map[dialogflow.ChangeAction]string{
+	dialogflow.ChangeActionAdd:    "+",
+	dialogflow.ChangeActionChange: "~",
+	dialogflow.ChangeActionRemove: "-",
+}
+
+func printPlan(w io.Writer, plan dialogflow.Plan) {
+	if len(plan.EntityTypes) == 0 && len(plan.Intents) == 0 {
+		fmt.Fprintln(w, "No changes.")
+		return
+	}
+
+	for _, change := range plan.EntityTypes {
+		fmt.Fprintf(w, "%s entity type %q%s\n", changeSymbols[change.Action], change.DisplayName, inLanguage(change.LanguageCode))
+		printFieldChanges(w, change.Fields)
+		for _, entity := range change.Entities {
+			fmt.Fprintf(w, "    %s entity %q\n", changeSymbols[entity.Action], entity.Value)
+			for _, synonym := range entity.Synonyms {
+				fmt.Fprintf(w, "        %s synonym %q\n", changeSymbols[synonym.Action], synonym.Value)
+			}
+		}
+	}
+
+	for _, change := range plan.Intents {
+		fmt.Fprintf(w, "%s intent %q%s\n", changeSymbols[change.Action], change.DisplayName, inLanguage(change.LanguageCode))
+		printFieldChanges(w, change.Fields)
+		printValueChanges(w, "training phrase", change.TrainingPhrases)
+		printValueChanges(w, "response", change.Responses)
+		printValueChanges(w, "context", change.Contexts)
+		printValueChanges(w, "parameter", change.Parameters)
+	}
+}
+
+// inLanguage returns the language of a change to a translation, or an empty
+// string for a change in the default language.
+func inLanguage(languageCode string) string {
+	if languageCode == "" {
+		return ""
+	}
+	return fmt.Sprintf(" in %q", languageCode)
+}
+
+func printFieldChanges(w io.Writer, fields []dialogflow.FieldChange) {
+	for _, field := range fields {
+		fmt.Fprintf(w, "    ~ %s: %q -> %q\n", field.Field, field.Old, field.New)
+	}
+}
+
+func printValueChanges(w io.Writer, kind string, values []dialogflow.ValueChange) {
+	for _, value := range values {
+		if value.Action == dialogflow.ChangeActionChange {
+			fmt.Fprintf(w, "    ~ %s %q -> %q\n", kind, value.Old, value.Value)
+			continue
+		}
+		fmt.Fprintf(w, "    %s %s %q\n", changeSymbols[value.Action], kind, value.Value)
+	}
+}
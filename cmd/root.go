@@ -1,12 +1,28 @@
 package cmd
 
 import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"google.golang.org/api/option"
 )
 
 var (
 	projectID       string
 	credentialsFile string
+	endpoint        string
+	timeout         time.Duration
+	waitTimeout     time.Duration
+
+	pollInterval = dialogflow.DefaultPollInterval
+	limits       = dialogflow.DefaultLimits
 
 	rootCmd = &cobra.Command{
 		Use:   "dialogflow-agent",
@@ -17,10 +33,79 @@ var (
 func init() {
 	rootCmd.PersistentFlags().StringVar(&projectID, "project-id", "", "project ID")
 	rootCmd.PersistentFlags().StringVar(&credentialsFile, "credentials-file", "credentials.json", "credentials file")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "timeout for the whole command, 0 for no timeout")
+	rootCmd.PersistentFlags().DurationVar(&waitTimeout, "wait-timeout", 10*time.Minute, "timeout for waiting on long-running operations such as batch deletes")
+	rootCmd.PersistentFlags().StringVar(&endpoint, "endpoint", "", "dialogflow API endpoint, empty for the default endpoint")
+	rootCmd.AddCommand(applyCmd)
+	rootCmd.AddCommand(chatCmd)
+	rootCmd.AddCommand(detectCmd)
 	rootCmd.AddCommand(entitiesCmd)
 	rootCmd.AddCommand(intentsCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(planCmd)
 }
 
 func Execute() error {
 	return rootCmd.Execute()
 }
+
+// addLimitsFlags adds the flags of the limits that the intents and entities
+// are checked against.
+func addLimitsFlags(flags *pflag.FlagSet) {
+	flags.IntVar(&limits.MaxDisplayNameLength, "max-display-name-length", limits.MaxDisplayNameLength, "maximum length of intent and entity names, 0 for no limit")
+	flags.IntVar(&limits.MaxTrainingPhrases, "max-training-phrases", limits.MaxTrainingPhrases, "maximum number of training phrases per intent, 0 for no limit")
+	flags.IntVar(&limits.MaxEntities, "max-entities", limits.MaxEntities, "maximum number of values per entity, 0 for no limit")
+	flags.IntVar(&limits.MaxResponseLength, "max-response-length", limits.MaxResponseLength, "maximum length of text responses, 0 for no limit")
+	flags.IntVar(&limits.MaxFollowupDepth, "max-followup-depth", limits.MaxFollowupDepth, "maximum depth of followup intents, 0 for no limit")
+}
+
+func clientOptions() []option.ClientOption {
+	opts := []option.ClientOption{option.WithCredentialsFile(credentialsFile)}
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+	return opts
+}
+
+// newContext returns the context for a command. It is cancelled when the
+// timeout passes or on the first interrupt, which stops the command before its
+// next operation. A second interrupt kills the process as usual.
+func newContext() (context.Context, context.CancelFunc) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		defer signal.Stop(signals)
+		select {
+		case sig := <-signals:
+			log.Printf("received %v, stopping", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// waitOperation waits until the operation is done, logging its progress, and
+// returns the error the server reported for it.
+func waitOperation(ctx context.Context, op dialogflow.Operation) error {
+	ctx, cancel := context.WithTimeout(ctx, waitTimeout)
+	defer cancel()
+
+	return dialogflow.WaitOperation(ctx, op, pollInterval, func(op dialogflow.Operation) {
+		if !op.Done() {
+			log.Printf("waiting for operation %s", op.Name())
+		}
+	})
+}
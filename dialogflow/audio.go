@@ -0,0 +1,124 @@
+package dialogflow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+)
+
+// audioFormat is the encoding and sample rate of speech audio.
+type audioFormat struct {
+	Encoding        dialogflowpb.AudioEncoding
+	SampleRateHertz int32
+}
+
+// audioHeaderSize is enough of the start of an audio file to read its format.
+const audioHeaderSize = 4096
+
+// readAudioFormat reads the format of the WAV, FLAC or OGG Opus audio that
+// starts with the header. Only mono audio is supported, as by Dialogflow.
+func readAudioFormat(header []byte) (audioFormat, error) {
+	switch {
+	case len(header) >= 12 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		return readWAVFormat(header)
+	case len(header) >= 4 && string(header[:4]) == "fLaC":
+		return readFLACFormat(header)
+	case len(header) >= 4 && string(header[:4]) == "OggS":
+		return readOggFormat(header)
+	default:
+		return audioFormat{}, errors.New("unknown audio format, must be WAV, FLAC or OGG Opus")
+	}
+}
+
+func readWAVFormat(header []byte) (audioFormat, error) {
+	// The fmt chunk follows the RIFF header, possibly after other chunks.
+	for offset := 12; offset+8 <= len(header); {
+		id := string(header[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(header[offset+4 : offset+8]))
+		offset += 8
+		if id != "fmt " {
+			offset += size + size%2
+			continue
+		}
+		if size < 16 || offset+16 > len(header) {
+			break
+		}
+
+		var (
+			format        = binary.LittleEndian.Uint16(header[offset:])
+			channels      = binary.LittleEndian.Uint16(header[offset+2:])
+			sampleRate    = binary.LittleEndian.Uint32(header[offset+4:])
+			bitsPerSample = binary.LittleEndian.Uint16(header[offset+14:])
+		)
+		if channels != 1 {
+			return audioFormat{}, fmt.Errorf("WAV audio has %d channels, must be mono", channels)
+		}
+		switch {
+		case format == 1 && bitsPerSample == 16:
+			return audioFormat{Encoding: dialogflowpb.AudioEncoding_AUDIO_ENCODING_LINEAR_16, SampleRateHertz: int32(sampleRate)}, nil
+		case format == 7 && bitsPerSample == 8:
+			return audioFormat{Encoding: dialogflowpb.AudioEncoding_AUDIO_ENCODING_MULAW, SampleRateHertz: int32(sampleRate)}, nil
+		default:
+			return audioFormat{}, fmt.Errorf("WAV audio must be 16-bit PCM or 8-bit mu-law, not format %d with %d bits", format, bitsPerSample)
+		}
+	}
+	return audioFormat{}, errors.New("WAV audio has no fmt chunk")
+}
+
+func readFLACFormat(header []byte) (audioFormat, error) {
+	// The STREAMINFO block comes first, after its 4 byte block header.
+	const streamInfo = 8
+	if len(header) < streamInfo+13 || header[4]&0x7f != 0 {
+		return audioFormat{}, errors.New("FLAC audio has no STREAMINFO block")
+	}
+	b := header[streamInfo:]
+	sampleRate := int32(b[10])<<12 | int32(b[11])<<4 | int32(b[12])>>4
+	if channels := (b[12]>>1)&0x07 + 1; channels != 1 {
+		return audioFormat{}, fmt.Errorf("FLAC audio has %d channels, must be mono", channels)
+	}
+	return audioFormat{Encoding: dialogflowpb.AudioEncoding_AUDIO_ENCODING_FLAC, SampleRateHertz: sampleRate}, nil
+}
+
+// opusSampleRates are the sample rates Dialogflow accepts for Opus audio.
+var opusSampleRates = map[int32]bool{8000: true, 12000: true, 16000: true, 24000: true, 48000: true}
+
+func readOggFormat(header []byte) (audioFormat, error) {
+	// The first page holds the OpusHead packet after its segment table.
+	if len(header) < 27 || len(header) < 27+int(header[26]) {
+		return audioFormat{}, errors.New("OGG audio has no first page")
+	}
+	packet := header[27+int(header[26]):]
+	if len(packet) < 16 || !bytes.HasPrefix(packet, []byte("OpusHead")) {
+		return audioFormat{}, errors.New("OGG audio must be Opus")
+	}
+	if channels := packet[9]; channels != 1 {
+		return audioFormat{}, fmt.Errorf("OGG audio has %d channels, must be mono", channels)
+	}
+	// Opus is decoded at 48 kHz, unless the original rate is supported.
+	sampleRate := int32(binary.LittleEndian.Uint32(packet[12:16]))
+	if !opusSampleRates[sampleRate] {
+		sampleRate = 48000
+	}
+	return audioFormat{Encoding: dialogflowpb.AudioEncoding_AUDIO_ENCODING_OGG_OPUS, SampleRateHertz: sampleRate}, nil
+}
+
+// outputAudioEncodings are the encodings of synthesized speech by file
+// extension.
+var outputAudioEncodings = map[string]dialogflowpb.OutputAudioEncoding{
+	".wav": dialogflowpb.OutputAudioEncoding_OUTPUT_AUDIO_ENCODING_LINEAR_16,
+	".mp3": dialogflowpb.OutputAudioEncoding_OUTPUT_AUDIO_ENCODING_MP3,
+	".ogg": dialogflowpb.OutputAudioEncoding_OUTPUT_AUDIO_ENCODING_OGG_OPUS,
+}
+
+func outputAudioEncoding(filename string) (dialogflowpb.OutputAudioEncoding, error) {
+	encoding, ok := outputAudioEncodings[strings.ToLower(filepath.Ext(filename))]
+	if !ok {
+		return 0, fmt.Errorf("unknown output audio file extension %q, must be .wav, .mp3 or .ogg", filepath.Ext(filename))
+	}
+	return encoding, nil
+}
@@ -0,0 +1,65 @@
+package dialogflowtest
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type agentsServer struct {
+	dialogflowpb.UnimplementedAgentsServer
+	s *Server
+}
+
+func (srv *agentsServer) GetAgent(_ context.Context, req *dialogflowpb.GetAgentRequest) (*dialogflowpb.Agent, error) {
+	if err := checkProject(req.Parent); err != nil {
+		return nil, err
+	}
+
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	agent := proto.Clone(srv.s.agent).(*dialogflowpb.Agent)
+	agent.Parent = req.Parent
+
+	return agent, nil
+}
+
+func (srv *agentsServer) SetAgent(_ context.Context, req *dialogflowpb.SetAgentRequest) (*dialogflowpb.Agent, error) {
+	if err := checkProject(req.Agent.GetParent()); err != nil {
+		return nil, err
+	}
+
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	if err := applyFieldMask(srv.s.agent, req.Agent, req.UpdateMask); err != nil {
+		return nil, err
+	}
+
+	return proto.Clone(srv.s.agent).(*dialogflowpb.Agent), nil
+}
+
+func (srv *agentsServer) TrainAgent(_ context.Context, req *dialogflowpb.TrainAgentRequest) (*longrunning.Operation, error) {
+	if err := checkProject(req.Parent); err != nil {
+		return nil, err
+	}
+
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	return srv.s.newOperation()
+}
+
+func checkProject(parent string) error {
+	parts := strings.Split(parent, "/")
+	if len(parts) != 2 || parts[0] != "projects" || parts[1] == "" {
+		return status.Errorf(codes.InvalidArgument, "invalid parent %q", parent)
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package dialogflowtest
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type entityTypesServer struct {
+	dialogflowpb.UnimplementedEntityTypesServer
+	s *Server
+}
+
+func (srv *entityTypesServer) ListEntityTypes(_ context.Context, req *dialogflowpb.ListEntityTypesRequest) (*dialogflowpb.ListEntityTypesResponse, error) {
+	if err := checkAgentParent(req.Parent); err != nil {
+		return nil, err
+	}
+
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	var entityTypes []*dialogflowpb.EntityType
+	for _, entityType := range srv.s.entityTypes {
+		if strings.HasPrefix(entityType.Name, req.Parent+"/") {
+			entityTypes = append(entityTypes, entityType)
+		}
+	}
+
+	start, end, nextPageToken, err := page(len(entityTypes), req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dialogflowpb.ListEntityTypesResponse{NextPageToken: nextPageToken}
+	for _, entityType := range entityTypes[start:end] {
+		response.EntityTypes = append(response.EntityTypes, srv.s.localizeEntityType(entityType, req.LanguageCode))
+	}
+
+	return response, nil
+}
+
+func (srv *entityTypesServer) GetEntityType(_ context.Context, req *dialogflowpb.GetEntityTypeRequest) (*dialogflowpb.EntityType, error) {
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	entityType, err := srv.s.getEntityType(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return srv.s.localizeEntityType(entityType, req.LanguageCode), nil
+}
+
+func (srv *entityTypesServer) CreateEntityType(_ context.Context, req *dialogflowpb.CreateEntityTypeRequest) (*dialogflowpb.EntityType, error) {
+	if err := checkAgentParent(req.Parent); err != nil {
+		return nil, err
+	}
+
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	entityType := proto.Clone(req.EntityType).(*dialogflowpb.EntityType)
+	if err := srv.s.checkEntityType(entityType, ""); err != nil {
+		return nil, err
+	}
+
+	entityType.Name = fmt.Sprintf("%s/entityTypes/%s", req.Parent, srv.s.newID())
+	normalizeEntities(entityType.Entities)
+
+	srv.s.entityTypes = append(srv.s.entityTypes, entityType)
+
+	return proto.Clone(entityType).(*dialogflowpb.EntityType), nil
+}
+
+func (srv *entityTypesServer) UpdateEntityType(_ context.Context, req *dialogflowpb.UpdateEntityTypeRequest) (*dialogflowpb.EntityType, error) {
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	entityType, err := srv.s.getEntityType(req.EntityType.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	updated := srv.s.localizeEntityType(entityType, req.LanguageCode)
+	if err := applyFieldMask(updated, req.EntityType, req.UpdateMask); err != nil {
+		return nil, err
+	}
+	if err := srv.s.checkEntityType(updated, entityType.Name); err != nil {
+		return nil, err
+	}
+
+	updated.Name = entityType.Name
+	normalizeEntities(updated.Entities)
+	if srv.s.isTranslation(req.LanguageCode) {
+		srv.s.setEntityTypeTranslation(updated, entityType, req.LanguageCode)
+	}
+
+	*entityType = *updated
+
+	return srv.s.localizeEntityType(entityType, req.LanguageCode), nil
+}
+
+func (srv *entityTypesServer) DeleteEntityType(_ context.Context, req *dialogflowpb.DeleteEntityTypeRequest) (*empty.Empty, error) {
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	if _, err := srv.s.getEntityType(req.Name); err != nil {
+		return nil, err
+	}
+
+	srv.s.deleteEntityTypes([]string{req.Name})
+
+	return &empty.Empty{}, nil
+}
+
+func (srv *entityTypesServer) BatchDeleteEntityTypes(_ context.Context, req *dialogflowpb.BatchDeleteEntityTypesRequest) (*longrunning.Operation, error) {
+	if err := checkAgentParent(req.Parent); err != nil {
+		return nil, err
+	}
+
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	for _, name := range req.EntityTypeNames {
+		if _, err := srv.s.getEntityType(name); err != nil {
+			return nil, err
+		}
+	}
+
+	srv.s.deleteEntityTypes(req.EntityTypeNames)
+
+	return srv.s.newOperation()
+}
+
+func (srv *entityTypesServer) BatchCreateEntities(_ context.Context, req *dialogflowpb.BatchCreateEntitiesRequest) (*longrunning.Operation, error) {
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	entityType, err := srv.s.getEntityType(req.Parent)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := srv.s.entities(entityType, req.LanguageCode)
+	values := make(map[string]bool)
+	for _, entity := range entities {
+		values[entity.Value] = true
+	}
+	for _, entity := range req.Entities {
+		if values[entity.Value] {
+			return nil, status.Errorf(codes.AlreadyExists, "entity %q already exists", entity.Value)
+		}
+	}
+
+	for _, entity := range req.Entities {
+		entities = append(entities, proto.Clone(entity).(*dialogflowpb.EntityType_Entity))
+	}
+	srv.s.setEntities(entityType, req.LanguageCode, entities)
+
+	return srv.s.newOperation()
+}
+
+func (srv *entityTypesServer) BatchUpdateEntities(_ context.Context, req *dialogflowpb.BatchUpdateEntitiesRequest) (*longrunning.Operation, error) {
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	entityType, err := srv.s.getEntityType(req.Parent)
+	if err != nil {
+		return nil, err
+	}
+
+	entities := append([]*dialogflowpb.EntityType_Entity(nil), srv.s.entities(entityType, req.LanguageCode)...)
+	for _, entity := range req.Entities {
+		updated := false
+		for i, existing := range entities {
+			if existing.Value != entity.Value {
+				continue
+			}
+			e := proto.Clone(existing).(*dialogflowpb.EntityType_Entity)
+			if err := applyFieldMask(e, entity, req.UpdateMask); err != nil {
+				return nil, err
+			}
+			entities[i] = e
+			updated = true
+		}
+		if !updated {
+			entities = append(entities, proto.Clone(entity).(*dialogflowpb.EntityType_Entity))
+		}
+	}
+	srv.s.setEntities(entityType, req.LanguageCode, entities)
+
+	return srv.s.newOperation()
+}
+
+func (srv *entityTypesServer) BatchDeleteEntities(_ context.Context, req *dialogflowpb.BatchDeleteEntitiesRequest) (*longrunning.Operation, error) {
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	entityType, err := srv.s.getEntityType(req.Parent)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make(map[string]bool)
+	for _, value := range req.EntityValues {
+		deleted[value] = true
+	}
+
+	var entities []*dialogflowpb.EntityType_Entity
+	for _, entity := range srv.s.entities(entityType, req.LanguageCode) {
+		if !deleted[entity.Value] {
+			entities = append(entities, entity)
+		}
+	}
+	srv.s.setEntities(entityType, req.LanguageCode, entities)
+
+	return srv.s.newOperation()
+}
+
+func (s *Server) getEntityType(name string) (*dialogflowpb.EntityType, error) {
+	for _, entityType := range s.entityTypes {
+		if entityType.Name == name {
+			return entityType, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "entity type %q not found", name)
+}
+
+func (s *Server) checkEntityType(entityType *dialogflowpb.EntityType, name string) error {
+	if entityType.DisplayName == "" {
+		return status.Error(codes.InvalidArgument, "entity type display name is empty")
+	}
+
+	for _, other := range s.entityTypes {
+		if other.Name != name && other.DisplayName == entityType.DisplayName {
+			return status.Errorf(codes.FailedPrecondition, "entity type with the display name %q already exists", entityType.DisplayName)
+		}
+	}
+
+	if entityType.Kind == dialogflowpb.EntityType_KIND_LIST {
+		for _, entity := range entityType.Entities {
+			for _, match := range entityReferenceRegexp.FindAllStringSubmatch(entity.Value, -1) {
+				if !strings.HasPrefix(match[1], "sys.") && !s.hasEntityType(match[1]) {
+					return status.Errorf(codes.InvalidArgument, "entity type %q references unknown entity type @%s", entityType.DisplayName, match[1])
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// entityReferenceRegexp matches the references to other entity types in the
+// values of composite entity types.
+var entityReferenceRegexp = regexp.MustCompile(`(?:^|[^\w@])@(\w[\w.-]*):`)
+
+func (s *Server) hasEntityType(displayName string) bool {
+	for _, entityType := range s.entityTypes {
+		if entityType.DisplayName == displayName {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) deleteEntityTypes(names []string) {
+	deleted := make(map[string]bool)
+	for _, name := range names {
+		deleted[name] = true
+	}
+
+	var entityTypes []*dialogflowpb.EntityType
+	for _, entityType := range s.entityTypes {
+		if !deleted[entityType.Name] {
+			entityTypes = append(entityTypes, entityType)
+		}
+	}
+	s.entityTypes = entityTypes
+}
+
+// normalizeEntities sets the synonyms of entities without synonyms to their
+// value, as Dialogflow does.
+func normalizeEntities(entities []*dialogflowpb.EntityType_Entity) {
+	for _, entity := range entities {
+		if len(entity.Synonyms) == 0 {
+			entity.Synonyms = []string{entity.Value}
+		}
+	}
+}
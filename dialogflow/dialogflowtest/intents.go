@@ -0,0 +1,283 @@
+package dialogflowtest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultPageSize = 100
+
+type intentsServer struct {
+	dialogflowpb.UnimplementedIntentsServer
+	s *Server
+}
+
+func (srv *intentsServer) ListIntents(_ context.Context, req *dialogflowpb.ListIntentsRequest) (*dialogflowpb.ListIntentsResponse, error) {
+	if err := checkAgentParent(req.Parent); err != nil {
+		return nil, err
+	}
+
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	var intents []*dialogflowpb.Intent
+	for _, intent := range srv.s.intents {
+		if strings.HasPrefix(intent.Name, req.Parent+"/") {
+			intents = append(intents, intent)
+		}
+	}
+
+	start, end, nextPageToken, err := page(len(intents), req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dialogflowpb.ListIntentsResponse{NextPageToken: nextPageToken}
+	for _, intent := range intents[start:end] {
+		response.Intents = append(response.Intents, srv.s.viewIntent(intent, req.IntentView, req.LanguageCode))
+	}
+
+	return response, nil
+}
+
+func (srv *intentsServer) GetIntent(_ context.Context, req *dialogflowpb.GetIntentRequest) (*dialogflowpb.Intent, error) {
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	intent := srv.s.findIntent(req.Name)
+	if intent == nil {
+		return nil, status.Errorf(codes.NotFound, "intent %q not found", req.Name)
+	}
+
+	return srv.s.viewIntent(intent, req.IntentView, req.LanguageCode), nil
+}
+
+func (srv *intentsServer) CreateIntent(_ context.Context, req *dialogflowpb.CreateIntentRequest) (*dialogflowpb.Intent, error) {
+	if err := checkAgentParent(req.Parent); err != nil {
+		return nil, err
+	}
+
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	intent := proto.Clone(req.Intent).(*dialogflowpb.Intent)
+	if err := srv.s.checkIntent(intent, ""); err != nil {
+		return nil, err
+	}
+
+	intent.Name = fmt.Sprintf("%s/intents/%s", req.Parent, srv.s.newID())
+	intent.FollowupIntentInfo = nil
+	setDefaultPriority(intent)
+	srv.s.setRootFollowupIntentName(intent)
+	srv.s.setTrainingPhraseNames(intent)
+
+	srv.s.intents = append(srv.s.intents, intent)
+
+	return srv.s.viewIntent(intent, req.IntentView, req.LanguageCode), nil
+}
+
+func (srv *intentsServer) UpdateIntent(_ context.Context, req *dialogflowpb.UpdateIntentRequest) (*dialogflowpb.Intent, error) {
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	intent := srv.s.findIntent(req.Intent.GetName())
+	if intent == nil {
+		return nil, status.Errorf(codes.NotFound, "intent %q not found", req.Intent.GetName())
+	}
+
+	updated := srv.s.localizeIntent(intent, req.LanguageCode)
+	if err := applyFieldMask(updated, req.Intent, req.UpdateMask); err != nil {
+		return nil, err
+	}
+	if err := srv.s.checkIntent(updated, intent.Name); err != nil {
+		return nil, err
+	}
+	if srv.s.isTranslation(req.LanguageCode) {
+		srv.s.setIntentTranslation(updated, intent, req.LanguageCode)
+	}
+
+	updated.Name = intent.Name
+	updated.FollowupIntentInfo = nil
+	setDefaultPriority(updated)
+	srv.s.setRootFollowupIntentName(updated)
+	srv.s.setTrainingPhraseNames(updated)
+
+	*intent = *updated
+
+	return srv.s.viewIntent(intent, req.IntentView, req.LanguageCode), nil
+}
+
+func (srv *intentsServer) DeleteIntent(_ context.Context, req *dialogflowpb.DeleteIntentRequest) (*empty.Empty, error) {
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	if srv.s.findIntent(req.Name) == nil {
+		return nil, status.Errorf(codes.NotFound, "intent %q not found", req.Name)
+	}
+
+	srv.s.deleteIntents([]string{req.Name})
+
+	return &empty.Empty{}, nil
+}
+
+func (srv *intentsServer) BatchDeleteIntents(_ context.Context, req *dialogflowpb.BatchDeleteIntentsRequest) (*longrunning.Operation, error) {
+	if err := checkAgentParent(req.Parent); err != nil {
+		return nil, err
+	}
+
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	var names []string
+	for _, intent := range req.Intents {
+		if srv.s.findIntent(intent.Name) == nil {
+			return nil, status.Errorf(codes.NotFound, "intent %q not found", intent.Name)
+		}
+		names = append(names, intent.Name)
+	}
+
+	srv.s.deleteIntents(names)
+
+	return srv.s.newOperation()
+}
+
+func (s *Server) findIntent(name string) *dialogflowpb.Intent {
+	for _, intent := range s.intents {
+		if intent.Name == name {
+			return intent
+		}
+	}
+	return nil
+}
+
+func (s *Server) checkIntent(intent *dialogflowpb.Intent, name string) error {
+	if intent.DisplayName == "" {
+		return status.Error(codes.InvalidArgument, "intent display name is empty")
+	}
+
+	for _, other := range s.intents {
+		if other.Name != name && other.DisplayName == intent.DisplayName {
+			return status.Errorf(codes.FailedPrecondition, "intent with the display name %q already exists", intent.DisplayName)
+		}
+	}
+
+	if intent.ParentFollowupIntentName != "" && s.findIntent(intent.ParentFollowupIntentName) == nil {
+		return status.Errorf(codes.InvalidArgument, "parent followup intent %q not found", intent.ParentFollowupIntentName)
+	}
+
+	return nil
+}
+
+// setDefaultPriority sets the priority of an intent without a priority to the
+// normal priority, as Dialogflow does.
+func setDefaultPriority(intent *dialogflowpb.Intent) {
+	if intent.Priority == 0 {
+		intent.Priority = 500000
+	}
+}
+
+func (s *Server) setRootFollowupIntentName(intent *dialogflowpb.Intent) {
+	intent.RootFollowupIntentName = ""
+	if intent.ParentFollowupIntentName == "" {
+		return
+	}
+
+	parent := s.findIntent(intent.ParentFollowupIntentName)
+	intent.RootFollowupIntentName = parent.RootFollowupIntentName
+	if intent.RootFollowupIntentName == "" {
+		intent.RootFollowupIntentName = parent.Name
+	}
+}
+
+func (s *Server) setTrainingPhraseNames(intent *dialogflowpb.Intent) {
+	for _, trainingPhrase := range intent.TrainingPhrases {
+		if trainingPhrase.Name == "" {
+			trainingPhrase.Name = s.newID()
+		}
+	}
+}
+
+// deleteIntents deletes the intents with the given names, together with all
+// their followup intents.
+func (s *Server) deleteIntents(names []string) {
+	deleted := make(map[string]bool)
+	for _, name := range names {
+		deleted[name] = true
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, intent := range s.intents {
+			if !deleted[intent.Name] && deleted[intent.ParentFollowupIntentName] {
+				deleted[intent.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	var intents []*dialogflowpb.Intent
+	for _, intent := range s.intents {
+		if !deleted[intent.Name] {
+			intents = append(intents, intent)
+		}
+	}
+	s.intents = intents
+}
+
+// fullIntent returns a copy of the intent with the followup intent info of all
+// intents in its followup tree.
+func (s *Server) fullIntent(intent *dialogflowpb.Intent) *dialogflowpb.Intent {
+	intent = proto.Clone(intent).(*dialogflowpb.Intent)
+	for _, other := range s.intents {
+		if other.RootFollowupIntentName == intent.Name {
+			intent.FollowupIntentInfo = append(intent.FollowupIntentInfo, &dialogflowpb.Intent_FollowupIntentInfo{
+				FollowupIntentName:       other.Name,
+				ParentFollowupIntentName: other.ParentFollowupIntentName,
+			})
+		}
+	}
+	return intent
+}
+
+// viewIntent returns a copy of the intent in the language as returned for the
+// given view, where only the full view includes the training phrases.
+func (s *Server) viewIntent(intent *dialogflowpb.Intent, intentView dialogflowpb.IntentView, languageCode string) *dialogflowpb.Intent {
+	intent = s.localizeIntent(s.fullIntent(intent), languageCode)
+	if intentView != dialogflowpb.IntentView_INTENT_VIEW_FULL {
+		intent.TrainingPhrases = nil
+	}
+	return intent
+}
+
+// page returns the bounds of the requested page and the token of the next
+// page, where page tokens are offsets.
+func page(n int, pageSize int32, pageToken string) (int, int, string, error) {
+	var start int
+	if pageToken != "" {
+		var err error
+		if start, err = strconv.Atoi(pageToken); err != nil || start < 0 || start > n {
+			return 0, 0, "", status.Errorf(codes.InvalidArgument, "invalid page token %q", pageToken)
+		}
+	}
+
+	size := int(pageSize)
+	if size <= 0 {
+		size = defaultPageSize
+	}
+
+	end := start + size
+	if end >= n {
+		return start, n, "", nil
+	}
+
+	return start, end, strconv.Itoa(end), nil
+}
@@ -0,0 +1,148 @@
+package dialogflowtest
+
+import (
+	"github.com/golang/protobuf/proto"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+)
+
+// isTranslation reports whether requests in the language are about the
+// translation of the agent in that language, rather than about the agent in
+// its default language.
+func (s *Server) isTranslation(languageCode string) bool {
+	return languageCode != "" && languageCode != s.agent.DefaultLanguageCode
+}
+
+// localizeIntent returns a copy of the intent with the training phrases,
+// messages and parameter prompts in the language.
+func (s *Server) localizeIntent(intent *dialogflowpb.Intent, languageCode string) *dialogflowpb.Intent {
+	intent = proto.Clone(intent).(*dialogflowpb.Intent)
+	if !s.isTranslation(languageCode) {
+		return intent
+	}
+
+	translation := s.intentTranslations[intent.Name][languageCode]
+	if translation == nil {
+		translation = &dialogflowpb.Intent{}
+	}
+	translation = proto.Clone(translation).(*dialogflowpb.Intent)
+
+	intent.TrainingPhrases = translation.TrainingPhrases
+	intent.Messages = translation.Messages
+	setParameterPrompts(intent.Parameters, translation.Parameters)
+
+	return intent
+}
+
+// setIntentTranslation stores the training phrases, messages and parameter
+// prompts of the intent as its translation in the language, and restores
+// them from the intent in the default language.
+func (s *Server) setIntentTranslation(updated, intent *dialogflowpb.Intent, languageCode string) {
+	translation := &dialogflowpb.Intent{
+		TrainingPhrases: updated.TrainingPhrases,
+		Messages:        updated.Messages,
+	}
+	for _, parameter := range updated.Parameters {
+		translation.Parameters = append(translation.Parameters, &dialogflowpb.Intent_Parameter{
+			DisplayName: parameter.DisplayName,
+			Prompts:     parameter.Prompts,
+		})
+	}
+
+	if s.intentTranslations[intent.Name] == nil {
+		s.intentTranslations[intent.Name] = make(map[string]*dialogflowpb.Intent)
+	}
+	s.intentTranslations[intent.Name][languageCode] = proto.Clone(translation).(*dialogflowpb.Intent)
+
+	intent = proto.Clone(intent).(*dialogflowpb.Intent)
+	updated.TrainingPhrases = intent.TrainingPhrases
+	updated.Messages = intent.Messages
+	setParameterPrompts(updated.Parameters, intent.Parameters)
+}
+
+// setParameterPrompts sets the prompts of the parameters to the prompts of
+// the translated parameters with the same display name.
+func setParameterPrompts(parameters, translatedParameters []*dialogflowpb.Intent_Parameter) {
+	for _, parameter := range parameters {
+		parameter.Prompts = nil
+		for _, translated := range translatedParameters {
+			if translated.DisplayName == parameter.DisplayName {
+				parameter.Prompts = translated.Prompts
+			}
+		}
+	}
+}
+
+// localizeEntityType returns a copy of the entity type with the entities in
+// the language.
+func (s *Server) localizeEntityType(entityType *dialogflowpb.EntityType, languageCode string) *dialogflowpb.EntityType {
+	entityType = proto.Clone(entityType).(*dialogflowpb.EntityType)
+	if !s.isTranslation(languageCode) {
+		return entityType
+	}
+
+	entityType.Entities = nil
+	for _, entity := range s.entityTypeTranslations[entityType.Name][languageCode] {
+		entityType.Entities = append(entityType.Entities, proto.Clone(entity).(*dialogflowpb.EntityType_Entity))
+	}
+
+	return entityType
+}
+
+// setEntityTypeTranslation stores the entities of the entity type as its
+// translation in the language, and restores them from the entity type in the
+// default language.
+func (s *Server) setEntityTypeTranslation(updated, entityType *dialogflowpb.EntityType, languageCode string) {
+	if s.entityTypeTranslations[entityType.Name] == nil {
+		s.entityTypeTranslations[entityType.Name] = make(map[string][]*dialogflowpb.EntityType_Entity)
+	}
+	s.entityTypeTranslations[entityType.Name][languageCode] = updated.Entities
+
+	updated.Entities = proto.Clone(entityType).(*dialogflowpb.EntityType).Entities
+}
+
+// entities returns the entities of the entity type in the language.
+func (s *Server) entities(entityType *dialogflowpb.EntityType, languageCode string) []*dialogflowpb.EntityType_Entity {
+	if !s.isTranslation(languageCode) {
+		return entityType.Entities
+	}
+	return s.entityTypeTranslations[entityType.Name][languageCode]
+}
+
+// setEntities sets the entities of the entity type in the language.
+func (s *Server) setEntities(entityType *dialogflowpb.EntityType, languageCode string, entities []*dialogflowpb.EntityType_Entity) {
+	normalizeEntities(entities)
+	if !s.isTranslation(languageCode) {
+		entityType.Entities = entities
+		return
+	}
+	if s.entityTypeTranslations[entityType.Name] == nil {
+		s.entityTypeTranslations[entityType.Name] = make(map[string][]*dialogflowpb.EntityType_Entity)
+	}
+	s.entityTypeTranslations[entityType.Name][languageCode] = entities
+}
+
+// LocalizedIntents returns a copy of the intents stored on the server in the
+// language.
+func (s *Server) LocalizedIntents(languageCode string) []*dialogflowpb.Intent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var intents []*dialogflowpb.Intent
+	for _, intent := range s.intents {
+		intents = append(intents, s.localizeIntent(s.fullIntent(intent), languageCode))
+	}
+	return intents
+}
+
+// LocalizedEntityTypes returns a copy of the entity types stored on the
+// server in the language.
+func (s *Server) LocalizedEntityTypes(languageCode string) []*dialogflowpb.EntityType {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entityTypes []*dialogflowpb.EntityType
+	for _, entityType := range s.entityTypes {
+		entityTypes = append(entityTypes, s.localizeEntityType(entityType, languageCode))
+	}
+	return entityTypes
+}
@@ -0,0 +1,83 @@
+package dialogflowtest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type operation struct {
+	polls int
+	err   error
+}
+
+// SetOperationResult makes the operations started after the call pending
+// until they are polled the given number of times. Done operations fail with
+// err, or succeed if err is nil. By default operations are done immediately.
+func (s *Server) SetOperationResult(polls int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.operationPolls = polls
+	s.operationErr = err
+}
+
+// newOperation returns a new operation, of which the changes are already
+// made, so only the reported state of the operation is delayed.
+func (s *Server) newOperation() (*longrunning.Operation, error) {
+	name := fmt.Sprintf("operations/%s", s.newID())
+	op := &operation{polls: s.operationPolls, err: s.operationErr}
+	s.operations[name] = op
+	return op.proto(name)
+}
+
+func (op *operation) proto(name string) (*longrunning.Operation, error) {
+	if op.polls > 0 {
+		return &longrunning.Operation{Name: name}, nil
+	}
+
+	if op.err != nil {
+		return &longrunning.Operation{
+			Name:   name,
+			Done:   true,
+			Result: &longrunning.Operation_Error{Error: status.Convert(op.err).Proto()},
+		}, nil
+	}
+
+	response, err := ptypes.MarshalAny(&empty.Empty{})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "marshal response: %v", err)
+	}
+
+	return &longrunning.Operation{
+		Name:   name,
+		Done:   true,
+		Result: &longrunning.Operation_Response{Response: response},
+	}, nil
+}
+
+type operationsServer struct {
+	longrunning.UnimplementedOperationsServer
+	s *Server
+}
+
+func (srv *operationsServer) GetOperation(_ context.Context, req *longrunning.GetOperationRequest) (*longrunning.Operation, error) {
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	op, ok := srv.s.operations[req.Name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "operation %q not found", req.Name)
+	}
+
+	if op.polls > 0 {
+		op.polls--
+	}
+
+	return op.proto(req.Name)
+}
@@ -0,0 +1,582 @@
+package dialogflowtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sync"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+)
+
+// Call is a method call recorded by one of the recorders, with the method
+// name and its arguments in order, leaving out the context.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+type recorder struct {
+	mu sync.Mutex
+
+	// Calls are the calls made so far, in order.
+	Calls []Call
+
+	// Errors are returned by the methods with the given names, after the call
+	// is recorded and before anything else is done. Calls with a done context
+	// return the context error instead.
+	Errors map[string]error
+
+	// OperationErrors are returned by the operations started by the methods
+	// with the given names, once they are polled.
+	OperationErrors map[string]error
+
+	lastID int
+}
+
+func newRecorder() recorder {
+	return recorder{
+		Errors:          make(map[string]error),
+		OperationErrors: make(map[string]error),
+	}
+}
+
+func (r *recorder) record(ctx context.Context, method string, args ...interface{}) error {
+	r.Calls = append(r.Calls, Call{Method: method, Args: args})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.Errors[method]
+}
+
+// Methods returns the names of the recorded calls, in order.
+func (r *recorder) Methods() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var methods []string
+	for _, call := range r.Calls {
+		methods = append(methods, call.Method)
+	}
+	return methods
+}
+
+func (r *recorder) newOperation(method string) *Operation {
+	r.lastID++
+	return &Operation{
+		OperationName: fmt.Sprintf("operations/%d", r.lastID),
+		Err:           r.OperationErrors[method],
+	}
+}
+
+func (r *recorder) newName(collection string) string {
+	r.lastID++
+	return fmt.Sprintf("projects/dialogflowtest/agent/%s/%d", collection, r.lastID)
+}
+
+// IntentsRecorder is a fake dialogflow.IntentsAPI that records every call and
+// keeps the intents in memory, in the default language only: the updates in
+// other languages are recorded but not kept.
+type IntentsRecorder struct {
+	recorder
+
+	// Intents are the intents as created, updated and deleted by the calls.
+	Intents []dialogflow.Intent
+}
+
+var _ dialogflow.IntentsAPI = (*IntentsRecorder)(nil)
+
+func NewIntentsRecorder() *IntentsRecorder {
+	return &IntentsRecorder{recorder: newRecorder()}
+}
+
+func (r *IntentsRecorder) ListIntents(ctx context.Context, intentView, languageCode string) ([]dialogflow.Intent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "ListIntents", intentView, languageCode); err != nil {
+		return nil, err
+	}
+
+	return append([]dialogflow.Intent(nil), r.Intents...), nil
+}
+
+func (r *IntentsRecorder) GetIntent(ctx context.Context, intentID, intentView string) (dialogflow.Intent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "GetIntent", intentID, intentView); err != nil {
+		return dialogflow.Intent{}, err
+	}
+
+	i := r.indexOf(intentID)
+	if i < 0 {
+		return dialogflow.Intent{}, fmt.Errorf("intent %q not found", intentID)
+	}
+
+	return r.Intents[i], nil
+}
+
+func (r *IntentsRecorder) CreateIntent(ctx context.Context, intent dialogflow.Intent) (dialogflow.Intent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "CreateIntent", intent); err != nil {
+		return dialogflow.Intent{}, err
+	}
+
+	return r.createIntent(intent), nil
+}
+
+func (r *IntentsRecorder) UpdateIntent(ctx context.Context, intent dialogflow.Intent, updateMask ...string) (dialogflow.Intent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "UpdateIntent", intent, updateMask); err != nil {
+		return dialogflow.Intent{}, err
+	}
+
+	i := r.indexOf(path.Base(intent.Name))
+	if i < 0 {
+		return dialogflow.Intent{}, fmt.Errorf("intent %q not found", intent.Name)
+	}
+	if intent.LanguageCode != "" {
+		return intent, nil
+	}
+	r.Intents[i] = intent
+
+	return intent, nil
+}
+
+func (r *IntentsRecorder) CreateFollowupIntent(ctx context.Context, followupIntent dialogflow.Intent, parentFollowupIntent dialogflow.Intent) (dialogflow.Intent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "CreateFollowupIntent", followupIntent, parentFollowupIntent); err != nil {
+		return dialogflow.Intent{}, err
+	}
+
+	followupIntent.ParentFollowupIntentName = parentFollowupIntent.Name
+
+	return r.createIntent(followupIntent), nil
+}
+
+func (r *IntentsRecorder) DeleteIntent(ctx context.Context, intentID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DeleteIntent", intentID); err != nil {
+		return err
+	}
+
+	i := r.indexOf(intentID)
+	if i < 0 {
+		return fmt.Errorf("intent %q not found", intentID)
+	}
+	r.deleteIntents([]string{r.Intents[i].Name})
+
+	return nil
+}
+
+func (r *IntentsRecorder) DeleteIntents(ctx context.Context, intents []dialogflow.Intent) (dialogflow.Operation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DeleteIntents", intents); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, intent := range intents {
+		names = append(names, intent.Name)
+	}
+	r.deleteIntents(names)
+
+	return r.newOperation("DeleteIntents"), nil
+}
+
+func (r *IntentsRecorder) createIntent(intent dialogflow.Intent) dialogflow.Intent {
+	intent.Name = r.newName("intents")
+	intent.FollowupIntents = nil
+	r.Intents = append(r.Intents, intent)
+	return intent
+}
+
+func (r *IntentsRecorder) indexOf(intentID string) int {
+	for i, intent := range r.Intents {
+		if path.Base(intent.Name) == intentID {
+			return i
+		}
+	}
+	return -1
+}
+
+// deleteIntents deletes the intents with the given names and their followup
+// intents.
+func (r *IntentsRecorder) deleteIntents(names []string) {
+	deleted := make(map[string]bool)
+	for _, name := range names {
+		deleted[name] = true
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, intent := range r.Intents {
+			if !deleted[intent.Name] && deleted[intent.ParentFollowupIntentName] {
+				deleted[intent.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	var intents []dialogflow.Intent
+	for _, intent := range r.Intents {
+		if !deleted[intent.Name] {
+			intents = append(intents, intent)
+		}
+	}
+	r.Intents = intents
+}
+
+// EntityTypesRecorder is a fake dialogflow.EntityTypesAPI that records every
+// call and keeps the entity types in memory, in the default language only:
+// the entities of other languages are recorded but not kept.
+type EntityTypesRecorder struct {
+	recorder
+
+	// EntityTypes are the entity types as created, updated and deleted by the
+	// calls.
+	EntityTypes []dialogflow.EntityType
+}
+
+var _ dialogflow.EntityTypesAPI = (*EntityTypesRecorder)(nil)
+
+func NewEntityTypesRecorder() *EntityTypesRecorder {
+	return &EntityTypesRecorder{recorder: newRecorder()}
+}
+
+func (r *EntityTypesRecorder) ListEntityTypes(ctx context.Context, languageCode string) ([]dialogflow.EntityType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "ListEntityTypes", languageCode); err != nil {
+		return nil, err
+	}
+
+	return append([]dialogflow.EntityType(nil), r.EntityTypes...), nil
+}
+
+func (r *EntityTypesRecorder) GetEntityType(ctx context.Context, entityTypeID string) (dialogflow.EntityType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "GetEntityType", entityTypeID); err != nil {
+		return dialogflow.EntityType{}, err
+	}
+
+	i, err := r.indexOf(entityTypeID)
+	if err != nil {
+		return dialogflow.EntityType{}, err
+	}
+
+	return r.EntityTypes[i], nil
+}
+
+func (r *EntityTypesRecorder) CreateEntityType(ctx context.Context, entityType dialogflow.EntityType) (dialogflow.EntityType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "CreateEntityType", entityType); err != nil {
+		return dialogflow.EntityType{}, err
+	}
+
+	entityType.Name = r.newName("entityTypes")
+	r.EntityTypes = append(r.EntityTypes, entityType)
+
+	return entityType, nil
+}
+
+func (r *EntityTypesRecorder) UpdateEntityType(ctx context.Context, entityType dialogflow.EntityType, updateMask ...string) (dialogflow.EntityType, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "UpdateEntityType", entityType, updateMask); err != nil {
+		return dialogflow.EntityType{}, err
+	}
+
+	i, err := r.indexOf(path.Base(entityType.Name))
+	if err != nil {
+		return dialogflow.EntityType{}, err
+	}
+	if entityType.LanguageCode != "" {
+		return entityType, nil
+	}
+	r.EntityTypes[i] = entityType
+
+	return entityType, nil
+}
+
+func (r *EntityTypesRecorder) DeleteEntityType(ctx context.Context, entityTypeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DeleteEntityType", entityTypeID); err != nil {
+		return err
+	}
+
+	i, err := r.indexOf(entityTypeID)
+	if err != nil {
+		return err
+	}
+	r.EntityTypes = append(r.EntityTypes[:i], r.EntityTypes[i+1:]...)
+
+	return nil
+}
+
+func (r *EntityTypesRecorder) DeleteEntityTypes(ctx context.Context, entityTypes []dialogflow.EntityType) (dialogflow.Operation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DeleteEntityTypes", entityTypes); err != nil {
+		return nil, err
+	}
+
+	for _, entityType := range entityTypes {
+		i, err := r.indexOf(path.Base(entityType.Name))
+		if err != nil {
+			return nil, err
+		}
+		r.EntityTypes = append(r.EntityTypes[:i], r.EntityTypes[i+1:]...)
+	}
+
+	return r.newOperation("DeleteEntityTypes"), nil
+}
+
+func (r *EntityTypesRecorder) BatchCreateEntities(ctx context.Context, entityTypeID, languageCode string, entities []dialogflow.Entity) (dialogflow.Operation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "BatchCreateEntities", entityTypeID, languageCode, entities); err != nil {
+		return nil, err
+	}
+	if languageCode != "" {
+		return r.newOperation("BatchCreateEntities"), nil
+	}
+
+	i, err := r.indexOf(entityTypeID)
+	if err != nil {
+		return nil, err
+	}
+	r.EntityTypes[i].Entities = append(append([]dialogflow.Entity(nil), r.EntityTypes[i].Entities...), entities...)
+
+	return r.newOperation("BatchCreateEntities"), nil
+}
+
+func (r *EntityTypesRecorder) BatchUpdateEntities(ctx context.Context, entityTypeID, languageCode string, entities []dialogflow.Entity, updateMask ...string) (dialogflow.Operation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "BatchUpdateEntities", entityTypeID, languageCode, entities, updateMask); err != nil {
+		return nil, err
+	}
+	if languageCode != "" {
+		return r.newOperation("BatchUpdateEntities"), nil
+	}
+
+	i, err := r.indexOf(entityTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make(map[string]dialogflow.Entity)
+	for _, entity := range entities {
+		updated[entity.Value] = entity
+	}
+
+	var result []dialogflow.Entity
+	for _, entity := range r.EntityTypes[i].Entities {
+		if e, ok := updated[entity.Value]; ok {
+			entity = e
+			delete(updated, entity.Value)
+		}
+		result = append(result, entity)
+	}
+	for _, entity := range entities {
+		if _, ok := updated[entity.Value]; ok {
+			result = append(result, entity)
+		}
+	}
+	r.EntityTypes[i].Entities = result
+
+	return r.newOperation("BatchUpdateEntities"), nil
+}
+
+func (r *EntityTypesRecorder) BatchDeleteEntities(ctx context.Context, entityTypeID, languageCode string, values []string) (dialogflow.Operation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "BatchDeleteEntities", entityTypeID, languageCode, values); err != nil {
+		return nil, err
+	}
+	if languageCode != "" {
+		return r.newOperation("BatchDeleteEntities"), nil
+	}
+
+	i, err := r.indexOf(entityTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := make(map[string]bool)
+	for _, value := range values {
+		deleted[value] = true
+	}
+
+	var result []dialogflow.Entity
+	for _, entity := range r.EntityTypes[i].Entities {
+		if !deleted[entity.Value] {
+			result = append(result, entity)
+		}
+	}
+	r.EntityTypes[i].Entities = result
+
+	return r.newOperation("BatchDeleteEntities"), nil
+}
+
+func (r *EntityTypesRecorder) indexOf(entityTypeID string) (int, error) {
+	for i, entityType := range r.EntityTypes {
+		if path.Base(entityType.Name) == entityTypeID {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("entity type %q not found", entityTypeID)
+}
+
+// SessionsRecorder is a fake dialogflow.SessionsAPI that records every call.
+type SessionsRecorder struct {
+	recorder
+
+	// Results are the results returned for the query texts, event names,
+	// audio filenames and streamed audio.
+	Results map[string]dialogflow.DetectIntentResult
+
+	// RecognitionResults are sent for the streamed audio.
+	RecognitionResults map[string][]dialogflow.RecognitionResult
+}
+
+var _ dialogflow.SessionsAPI = (*SessionsRecorder)(nil)
+
+func NewSessionsRecorder() *SessionsRecorder {
+	return &SessionsRecorder{
+		recorder:           newRecorder(),
+		Results:            make(map[string]dialogflow.DetectIntentResult),
+		RecognitionResults: make(map[string][]dialogflow.RecognitionResult),
+	}
+}
+
+func (r *SessionsRecorder) DetectIntentText(ctx context.Context, sessionID, text, languageCode string, opts dialogflow.DetectIntentOptions) (dialogflow.DetectIntentResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DetectIntentText", sessionID, text, languageCode, opts); err != nil {
+		return dialogflow.DetectIntentResult{}, err
+	}
+
+	return r.Results[text], nil
+}
+
+func (r *SessionsRecorder) DetectIntentEvent(ctx context.Context, sessionID, name string, params map[string]interface{}, languageCode string, opts dialogflow.DetectIntentOptions) (dialogflow.DetectIntentResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DetectIntentEvent", sessionID, name, params, languageCode, opts); err != nil {
+		return dialogflow.DetectIntentResult{}, err
+	}
+
+	return r.Results[name], nil
+}
+
+func (r *SessionsRecorder) DetectIntentAudio(ctx context.Context, sessionID string, audio dialogflow.AudioFile, opts dialogflow.DetectIntentOptions) (dialogflow.DetectIntentResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.record(ctx, "DetectIntentAudio", sessionID, audio, opts); err != nil {
+		return dialogflow.DetectIntentResult{}, err
+	}
+
+	return r.Results[audio.Filename], nil
+}
+
+// StreamingDetectIntent records the streamed audio as a byte slice.
+func (r *SessionsRecorder) StreamingDetectIntent(ctx context.Context, sessionID string, audio io.Reader, languageCode string, opts dialogflow.DetectIntentOptions, recognitionResults chan<- dialogflow.RecognitionResult) (dialogflow.DetectIntentResult, error) {
+	if recognitionResults != nil {
+		defer close(recognitionResults)
+	}
+
+	data, err := ioutil.ReadAll(audio)
+	if err != nil {
+		return dialogflow.DetectIntentResult{}, fmt.Errorf("read audio: %v", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.record(ctx, "StreamingDetectIntent", sessionID, data, languageCode, opts); err != nil {
+		return dialogflow.DetectIntentResult{}, err
+	}
+
+	if recognitionResults != nil {
+		for _, result := range r.RecognitionResults[string(data)] {
+			select {
+			case recognitionResults <- result:
+			case <-ctx.Done():
+				return dialogflow.DetectIntentResult{}, ctx.Err()
+			}
+		}
+	}
+	return r.Results[string(data)], nil
+}
+
+// Operation is a fake dialogflow.Operation. It is done after it is polled
+// Polls times, and then returns Err.
+type Operation struct {
+	OperationName string
+	Polls         int
+	Err           error
+
+	mu     sync.Mutex
+	polled int
+}
+
+var _ dialogflow.Operation = (*Operation)(nil)
+
+func (op *Operation) Name() string {
+	return op.OperationName
+}
+
+func (op *Operation) Done() bool {
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	return op.polled >= op.Polls
+}
+
+func (op *Operation) Poll(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	op.mu.Lock()
+	defer op.mu.Unlock()
+
+	if op.polled < op.Polls {
+		op.polled++
+	}
+	if op.polled < op.Polls {
+		return nil
+	}
+
+	return op.Err
+}
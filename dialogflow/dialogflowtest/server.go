@@ -0,0 +1,194 @@
+// Package dialogflowtest provides an in-memory Dialogflow v2 server for
+// testing code that talks to the Dialogflow API without credentials or a
+// network connection.
+package dialogflowtest
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/api/option"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+	"google.golang.org/genproto/googleapis/longrunning"
+	"google.golang.org/genproto/protobuf/field_mask"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server is a gRPC server implementing the Agents, EntityTypes, Intents and
+// Sessions services. All state is kept in memory and lost on Close.
+type Server struct {
+	// Addr is the address the server listens on, in the form host:port.
+	Addr string
+
+	listener net.Listener
+	server   *grpc.Server
+
+	mu          sync.Mutex
+	lastID      int
+	agent       *dialogflowpb.Agent
+	intents     []*dialogflowpb.Intent
+	entityTypes []*dialogflowpb.EntityType
+
+	// The translations of the intents and entity types by their name and
+	// language code.
+	intentTranslations     map[string]map[string]*dialogflowpb.Intent
+	entityTypeTranslations map[string]map[string][]*dialogflowpb.EntityType_Entity
+
+	operations     map[string]*operation
+	operationPolls int
+	operationErr   error
+
+	detectIntentRequests []*dialogflowpb.DetectIntentRequest
+	transcripts          map[string]string
+}
+
+// NewServer starts and returns a new server listening on a local port. The
+// caller should call Close when finished, to shut it down. NewServer panics
+// if it cannot listen, like httptest.NewServer.
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("dialogflowtest: failed to listen on a port: %v", err))
+	}
+
+	s := &Server{
+		Addr:     listener.Addr().String(),
+		listener: listener,
+		server:   grpc.NewServer(),
+		agent: &dialogflowpb.Agent{
+			DisplayName:         "dialogflowtest",
+			DefaultLanguageCode: "en",
+			TimeZone:            "UTC",
+		},
+		intentTranslations:     make(map[string]map[string]*dialogflowpb.Intent),
+		entityTypeTranslations: make(map[string]map[string][]*dialogflowpb.EntityType_Entity),
+		operations:             make(map[string]*operation),
+		transcripts:            make(map[string]string),
+	}
+
+	dialogflowpb.RegisterAgentsServer(s.server, &agentsServer{s: s})
+	dialogflowpb.RegisterEntityTypesServer(s.server, &entityTypesServer{s: s})
+	dialogflowpb.RegisterIntentsServer(s.server, &intentsServer{s: s})
+	dialogflowpb.RegisterSessionsServer(s.server, &sessionsServer{s: s})
+	longrunning.RegisterOperationsServer(s.server, &operationsServer{s: s})
+
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+
+	return s
+}
+
+// ClientOptions returns the options to connect a Dialogflow client to the
+// server.
+func (s *Server) ClientOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(s.Addr),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithInsecure()),
+	}
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Stop()
+}
+
+// Intents returns a copy of the intents stored on the server.
+func (s *Server) Intents() []*dialogflowpb.Intent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var intents []*dialogflowpb.Intent
+	for _, intent := range s.intents {
+		intents = append(intents, s.fullIntent(intent))
+	}
+	return intents
+}
+
+// EntityTypes returns a copy of the entity types stored on the server.
+func (s *Server) EntityTypes() []*dialogflowpb.EntityType {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entityTypes []*dialogflowpb.EntityType
+	for _, entityType := range s.entityTypes {
+		entityTypes = append(entityTypes, proto.Clone(entityType).(*dialogflowpb.EntityType))
+	}
+	return entityTypes
+}
+
+// SetTranscript sets the text that audio queries with exactly the audio are
+// recognized as. Other audio is not recognized.
+func (s *Server) SetTranscript(audio []byte, transcript string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.transcripts[string(audio)] = transcript
+}
+
+// DetectIntentRequests returns a copy of the detect intent requests the server
+// received, in order.
+func (s *Server) DetectIntentRequests() []*dialogflowpb.DetectIntentRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var requests []*dialogflowpb.DetectIntentRequest
+	for _, request := range s.detectIntentRequests {
+		requests = append(requests, proto.Clone(request).(*dialogflowpb.DetectIntentRequest))
+	}
+	return requests
+}
+
+func (s *Server) newID() string {
+	s.lastID++
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", s.lastID)
+}
+
+func checkAgentParent(parent string) error {
+	parts := strings.Split(parent, "/")
+	if len(parts) != 3 || parts[0] != "projects" || parts[1] == "" || parts[2] != "agent" {
+		return status.Errorf(codes.InvalidArgument, "invalid parent %q", parent)
+	}
+	return nil
+}
+
+// applyFieldMask copies the fields in the mask from src to dst, or all fields
+// if the mask is empty. Paths are matched against the protobuf field names.
+func applyFieldMask(dst, src proto.Message, mask *field_mask.FieldMask) error {
+	if mask == nil || len(mask.Paths) == 0 {
+		dst.Reset()
+		proto.Merge(dst, src)
+		return nil
+	}
+
+	dstValue := reflect.ValueOf(dst).Elem()
+	srcValue := reflect.ValueOf(src).Elem()
+
+	for _, path := range mask.Paths {
+		i := fieldIndex(dstValue.Type(), path)
+		if i < 0 {
+			return status.Errorf(codes.InvalidArgument, "invalid update mask path %q", path)
+		}
+		dstValue.Field(i).Set(srcValue.Field(i))
+	}
+
+	return nil
+}
+
+func fieldIndex(t reflect.Type, name string) int {
+	for i := 0; i < t.NumField(); i++ {
+		for _, option := range strings.Split(t.Field(i).Tag.Get("protobuf"), ",") {
+			if option == "name="+name {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,266 @@
+package dialogflowtest
+
+import (
+	"context"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type sessionsServer struct {
+	dialogflowpb.UnimplementedSessionsServer
+	s *Server
+}
+
+// DetectIntent matches text queries against the training phrases of the
+// intents, ignoring case, and event queries against their events. Audio
+// queries are matched as text by the transcript of their audio. Queries that
+// do not match an intent are matched to the fallback intent, if any. The
+// contexts of the query parameters are returned as output contexts, unless the
+// intent sets them, and a requested sentiment is always neutral. Requested
+// output audio holds the fulfillment text.
+func (srv *sessionsServer) DetectIntent(_ context.Context, req *dialogflowpb.DetectIntentRequest) (*dialogflowpb.DetectIntentResponse, error) {
+	return srv.detectIntent(req)
+}
+
+// StreamingDetectIntent recognizes the streamed audio by its transcript. While
+// the audio is received, the words of the transcript are recognized in
+// proportion to the audio received so far. Once the client closes its side of
+// the stream, the final transcript and the query result of DetectIntent
+// follow.
+func (srv *sessionsServer) StreamingDetectIntent(stream dialogflowpb.Sessions_StreamingDetectIntentServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if first.QueryInput.GetAudioConfig() == nil {
+		return status.Error(codes.InvalidArgument, "query input must be audio")
+	}
+
+	var (
+		audio   = append([]byte(nil), first.InputAudio...)
+		interim string
+	)
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		audio = append(audio, req.InputAudio...)
+
+		if transcript := srv.s.interimTranscript(audio); transcript != "" && transcript != interim {
+			interim = transcript
+			if err = stream.Send(&dialogflowpb.StreamingDetectIntentResponse{
+				RecognitionResult: &dialogflowpb.StreamingRecognitionResult{
+					MessageType: dialogflowpb.StreamingRecognitionResult_TRANSCRIPT,
+					Transcript:  transcript,
+				},
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	response, err := srv.detectIntent(&dialogflowpb.DetectIntentRequest{
+		Session:           first.Session,
+		QueryParams:       first.QueryParams,
+		QueryInput:        first.QueryInput,
+		OutputAudioConfig: first.OutputAudioConfig,
+		InputAudio:        audio,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = stream.Send(&dialogflowpb.StreamingDetectIntentResponse{
+		RecognitionResult: &dialogflowpb.StreamingRecognitionResult{
+			MessageType: dialogflowpb.StreamingRecognitionResult_TRANSCRIPT,
+			Transcript:  response.QueryResult.QueryText,
+			IsFinal:     true,
+			Confidence:  1,
+		},
+	}); err != nil {
+		return err
+	}
+	return stream.Send(&dialogflowpb.StreamingDetectIntentResponse{
+		ResponseId:        response.ResponseId,
+		QueryResult:       response.QueryResult,
+		OutputAudio:       response.OutputAudio,
+		OutputAudioConfig: response.OutputAudioConfig,
+	})
+}
+
+func (srv *sessionsServer) detectIntent(req *dialogflowpb.DetectIntentRequest) (*dialogflowpb.DetectIntentResponse, error) {
+	parts := strings.Split(req.Session, "/")
+	if len(parts) != 5 || parts[0] != "projects" || parts[2] != "agent" || parts[3] != "sessions" || parts[4] == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session %q", req.Session)
+	}
+
+	srv.s.mu.Lock()
+	defer srv.s.mu.Unlock()
+
+	srv.s.detectIntentRequests = append(srv.s.detectIntentRequests, proto.Clone(req).(*dialogflowpb.DetectIntentRequest))
+
+	queryResult := &dialogflowpb.QueryResult{}
+
+	var (
+		intent *dialogflowpb.Intent
+		params map[string]string
+	)
+	switch input := req.QueryInput.GetInput().(type) {
+	case *dialogflowpb.QueryInput_Text:
+		queryResult.QueryText = input.Text.Text
+		queryResult.LanguageCode = input.Text.LanguageCode
+		intent, params = srv.s.matchText(input.Text.Text, input.Text.LanguageCode)
+	case *dialogflowpb.QueryInput_Event:
+		queryResult.QueryText = input.Event.Name
+		queryResult.LanguageCode = input.Event.LanguageCode
+		intent = srv.s.matchEvent(input.Event.Name)
+	case *dialogflowpb.QueryInput_AudioConfig:
+		if len(req.InputAudio) == 0 {
+			return nil, status.Error(codes.InvalidArgument, "input audio is empty")
+		}
+		queryResult.QueryText = srv.s.transcripts[string(req.InputAudio)]
+		queryResult.LanguageCode = input.AudioConfig.LanguageCode
+		queryResult.SpeechRecognitionConfidence = 1
+		intent, params = srv.s.matchText(queryResult.QueryText, input.AudioConfig.LanguageCode)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "query input must be text, event or audio")
+	}
+
+	if intent == nil {
+		intent = srv.s.fallbackIntent()
+	}
+
+	if intent != nil {
+		setQueryResultIntent(queryResult, srv.s.localizeIntent(intent, queryResult.LanguageCode), params, req.Session)
+	}
+	addQueryContexts(queryResult, req.QueryParams.GetContexts())
+	if req.QueryParams.GetSentimentAnalysisRequestConfig().GetAnalyzeQueryTextSentiment() {
+		queryResult.SentimentAnalysisResult = &dialogflowpb.SentimentAnalysisResult{
+			QueryTextSentiment: &dialogflowpb.Sentiment{},
+		}
+	}
+
+	response := &dialogflowpb.DetectIntentResponse{
+		ResponseId:  srv.s.newID(),
+		QueryResult: queryResult,
+	}
+	if req.OutputAudioConfig != nil {
+		response.OutputAudio = []byte(queryResult.FulfillmentText)
+		response.OutputAudioConfig = proto.Clone(req.OutputAudioConfig).(*dialogflowpb.OutputAudioConfig)
+	}
+	return response, nil
+}
+
+// interimTranscript returns the words of the transcript of the audio that
+// starts with the partial audio, in proportion to its length.
+func (s *Server) interimTranscript(partial []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for audio, transcript := range s.transcripts {
+		if strings.HasPrefix(audio, string(partial)) {
+			words := strings.Fields(transcript)
+			return strings.Join(words[:len(words)*len(partial)/len(audio)], " ")
+		}
+	}
+	return ""
+}
+
+func (s *Server) matchText(text, languageCode string) (*dialogflowpb.Intent, map[string]string) {
+	for _, intent := range s.intents {
+		intent = s.localizeIntent(intent, languageCode)
+		for _, trainingPhrase := range intent.TrainingPhrases {
+			var (
+				phrase string
+				params = make(map[string]string)
+			)
+			for _, part := range trainingPhrase.Parts {
+				phrase += part.Text
+				if part.Alias != "" {
+					params[part.Alias] = part.Text
+				}
+			}
+			if strings.EqualFold(strings.TrimSpace(phrase), strings.TrimSpace(text)) {
+				return intent, params
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) matchEvent(event string) *dialogflowpb.Intent {
+	for _, intent := range s.intents {
+		for _, e := range intent.Events {
+			if e == event {
+				return intent
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) fallbackIntent() *dialogflowpb.Intent {
+	for _, intent := range s.intents {
+		if intent.IsFallback {
+			return intent
+		}
+	}
+	return nil
+}
+
+func addQueryContexts(queryResult *dialogflowpb.QueryResult, contexts []*dialogflowpb.Context) {
+	for _, queryContext := range contexts {
+		set := false
+		for _, outputContext := range queryResult.OutputContexts {
+			if path.Base(outputContext.Name) == path.Base(queryContext.Name) {
+				set = true
+			}
+		}
+		if !set {
+			queryResult.OutputContexts = append(queryResult.OutputContexts, proto.Clone(queryContext).(*dialogflowpb.Context))
+		}
+	}
+}
+
+func setQueryResultIntent(queryResult *dialogflowpb.QueryResult, intent *dialogflowpb.Intent, params map[string]string, session string) {
+	queryResult.Intent = &dialogflowpb.Intent{
+		Name:        intent.Name,
+		DisplayName: intent.DisplayName,
+		IsFallback:  intent.IsFallback,
+	}
+	queryResult.IntentDetectionConfidence = 1
+	queryResult.Action = intent.Action
+	queryResult.AllRequiredParamsPresent = true
+
+	queryResult.Parameters = &structpb.Struct{Fields: make(map[string]*structpb.Value)}
+	for _, parameter := range intent.Parameters {
+		queryResult.Parameters.Fields[parameter.DisplayName] = &structpb.Value{
+			Kind: &structpb.Value_StringValue{StringValue: params[parameter.DisplayName]},
+		}
+	}
+
+	for _, message := range intent.Messages {
+		queryResult.FulfillmentMessages = append(queryResult.FulfillmentMessages, proto.Clone(message).(*dialogflowpb.Intent_Message))
+		if text := message.GetText(); text != nil && queryResult.FulfillmentText == "" &&
+			message.Platform == dialogflowpb.Intent_Message_PLATFORM_UNSPECIFIED && len(text.Text) > 0 {
+			queryResult.FulfillmentText = text.Text[0]
+		}
+	}
+
+	for _, outputContext := range intent.OutputContexts {
+		outputContext = proto.Clone(outputContext).(*dialogflowpb.Context)
+		outputContext.Name = session + "/contexts/" + path.Base(outputContext.Name)
+		queryResult.OutputContexts = append(queryResult.OutputContexts, outputContext)
+	}
+}
@@ -0,0 +1,210 @@
+package dialogflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+)
+
+// systemEntityTypes are the system entity types of Dialogflow.
+var systemEntityTypes = map[string]bool{
+	"sys.address":               true,
+	"sys.age":                   true,
+	"sys.airport":               true,
+	"sys.any":                   true,
+	"sys.cardinal":              true,
+	"sys.color":                 true,
+	"sys.currency-name":         true,
+	"sys.date":                  true,
+	"sys.date-period":           true,
+	"sys.date-time":             true,
+	"sys.duration":              true,
+	"sys.email":                 true,
+	"sys.flight-number":         true,
+	"sys.geo-capital":           true,
+	"sys.geo-city":              true,
+	"sys.geo-city-gb":           true,
+	"sys.geo-city-us":           true,
+	"sys.geo-country":           true,
+	"sys.geo-country-code":      true,
+	"sys.geo-state":             true,
+	"sys.geo-state-gb":          true,
+	"sys.geo-state-us":          true,
+	"sys.given-name":            true,
+	"sys.language":              true,
+	"sys.last-name":             true,
+	"sys.location":              true,
+	"sys.music-artist":          true,
+	"sys.music-genre":           true,
+	"sys.number":                true,
+	"sys.number-integer":        true,
+	"sys.number-sequence":       true,
+	"sys.ordinal":               true,
+	"sys.percentage":            true,
+	"sys.person":                true,
+	"sys.phone-number":          true,
+	"sys.place-attraction":      true,
+	"sys.place-attraction-gb":   true,
+	"sys.place-attraction-us":   true,
+	"sys.street-address":        true,
+	"sys.temperature":           true,
+	"sys.time":                  true,
+	"sys.time-period":           true,
+	"sys.unit-area":             true,
+	"sys.unit-area-name":        true,
+	"sys.unit-currency":         true,
+	"sys.unit-information":      true,
+	"sys.unit-information-name": true,
+	"sys.unit-length":           true,
+	"sys.unit-length-name":      true,
+	"sys.unit-speed":            true,
+	"sys.unit-speed-name":       true,
+	"sys.unit-volume":           true,
+	"sys.unit-volume-name":      true,
+	"sys.unit-weight":           true,
+	"sys.unit-weight-name":      true,
+	"sys.url":                   true,
+	"sys.zip-code":              true,
+}
+
+// isSystemEntityType reports whether the entity type, such as "@sys.date" or
+// "sys.date", is a system entity type.
+func isSystemEntityType(entityType string) bool {
+	return systemEntityTypes[strings.TrimPrefix(entityType, "@")]
+}
+
+// entityReferenceRegexp matches a reference to an entity type in an entity
+// value of a composite entity type, such as @sys.person:person.
+var entityReferenceRegexp = regexp.MustCompile(`(^|[^\w@])@(\w[\w.-]*)(:[\w-]+)?`)
+
+type entityReference struct {
+	EntityType string
+	Alias      string
+}
+
+// entityReferences returns the references to entity types in an entity value.
+func entityReferences(value string) ([]entityReference, error) {
+	var references []entityReference
+	for _, match := range entityReferenceRegexp.FindAllStringSubmatch(value, -1) {
+		if match[3] == "" {
+			return nil, fmt.Errorf("reference @%s in value %q has no alias", match[2], value)
+		}
+		references = append(references, entityReference{
+			EntityType: match[2],
+			Alias:      match[3][1:],
+		})
+	}
+	return references, nil
+}
+
+// checkEntityTypeValues checks the values of the entity type for its kind:
+// regexp entity types must have valid regular expressions, and only list
+// entity types can reference other entity types.
+func checkEntityTypeValues(entityType EntityType) error {
+	for _, entity := range entityType.Entities {
+		if entityType.Kind == dialogflowpb.EntityType_KIND_REGEXP.String() {
+			if _, err := regexp.Compile(entity.Value); err != nil {
+				return fmt.Errorf("value %q: invalid regular expression: %v", entity.Value, err)
+			}
+			continue
+		}
+		references, err := entityReferences(entity.Value)
+		if err != nil {
+			return err
+		}
+		if len(references) > 0 && entityType.Kind != dialogflowpb.EntityType_KIND_LIST.String() {
+			return fmt.Errorf("value %q: only list entity types can reference entity types", entity.Value)
+		}
+	}
+	return nil
+}
+
+// checkEntityReferences checks that every entity type referenced by the
+// entity types is a system entity type or one of the entity types or remote
+// entity types.
+func checkEntityReferences(entityTypes, remoteEntityTypes []EntityType) error {
+	known := make(map[string]bool)
+	for _, entityType := range entityTypes {
+		known[entityType.DisplayName] = true
+	}
+	for _, entityType := range remoteEntityTypes {
+		known[entityType.DisplayName] = true
+	}
+
+	for _, entityType := range entityTypes {
+		for _, reference := range compositeReferences(entityType) {
+			if !known[reference] && !isSystemEntityType(reference) {
+				return fmt.Errorf("entity type %q references unknown entity type @%s", entityType.DisplayName, reference)
+			}
+		}
+	}
+	return nil
+}
+
+// compositeReferences returns the entity types referenced by the values of a
+// list entity type.
+func compositeReferences(entityType EntityType) []string {
+	if entityType.Kind != dialogflowpb.EntityType_KIND_LIST.String() {
+		return nil
+	}
+	var references []string
+	for _, entity := range entityType.Entities {
+		// The values were checked when they were read.
+		refs, _ := entityReferences(entity.Value)
+		for _, ref := range refs {
+			references = append(references, ref.EntityType)
+		}
+	}
+	return references
+}
+
+// sortEntityTypes sorts the entity types so that entity types come after the
+// entity types they reference, and otherwise keeps their order.
+func sortEntityTypes(entityTypes []EntityType) ([]EntityType, error) {
+	index := make(map[string]int)
+	for i, entityType := range entityTypes {
+		index[entityType.DisplayName] = i
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	var (
+		state  = make([]int, len(entityTypes))
+		sorted []EntityType
+		visit  func(i int, path []string) error
+	)
+	visit = func(i int, path []string) error {
+		path = append(path, entityTypes[i].DisplayName)
+		switch state[i] {
+		case visiting:
+			return fmt.Errorf("entity types reference each other: %s", strings.Join(path, " -> "))
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		references := compositeReferences(entityTypes[i])
+		sort.Strings(references)
+		for _, reference := range references {
+			if j, ok := index[reference]; ok {
+				if err := visit(j, path); err != nil {
+					return err
+				}
+			}
+		}
+		state[i] = visited
+		sorted = append(sorted, entityTypes[i])
+		return nil
+	}
+
+	for i := range entityTypes {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
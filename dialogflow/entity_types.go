@@ -7,6 +7,13 @@ type EntityType struct {
 	AutoExpansionMode     string
 	Entities              []Entity
 	EnableFuzzyExtraction bool
+
+	// LanguageCode is the language of the entities, or empty for the
+	// default language of the agent.
+	LanguageCode string
+	// Translations hold the entities of the entity type in other
+	// languages.
+	Translations []EntityType
 }
 
 type Entity struct {
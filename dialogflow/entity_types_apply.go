@@ -0,0 +1,188 @@
+package dialogflow
+
+import (
+	"context"
+	"fmt"
+	"path"
+)
+
+type EntityTypesApplier interface {
+	PlanEntityTypes(ctx context.Context) ([]EntityTypeChange, error)
+	ApplyEntityTypes(ctx context.Context) error
+	PruneEntityTypes(ctx context.Context) error
+}
+
+type entityTypesApplier struct {
+	entityTypesClient EntityTypesAPI
+	source            Source
+}
+
+func NewEntityTypesApplier(entityTypesClient EntityTypesAPI, source Source) EntityTypesApplier {
+	return &entityTypesApplier{
+		entityTypesClient: entityTypesClient,
+		source:            source,
+	}
+}
+
+func (applier *entityTypesApplier) PlanEntityTypes(ctx context.Context) ([]EntityTypeChange, error) {
+	data, err := applier.source.ReadAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read data: %v", err)
+	}
+
+	entityTypes, err := readEntityTypes(data)
+	if err != nil {
+		return nil, fmt.Errorf("read entity types: %v", sourceError(applier.source, err))
+	}
+
+	remoteEntityTypes, err := applier.entityTypesClient.ListEntityTypes(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("list entity types: %v", err)
+	}
+
+	remoteTranslations := make(map[string][]EntityType)
+	for _, languageCode := range entityTypeLanguageCodes(entityTypes) {
+		remoteTranslations[languageCode], err = applier.entityTypesClient.ListEntityTypes(ctx, languageCode)
+		if err != nil {
+			return nil, fmt.Errorf("list entity types in language %q: %v", languageCode, err)
+		}
+	}
+
+	if err = checkEntityReferences(entityTypes, remoteEntityTypes); err != nil {
+		return nil, err
+	}
+
+	return planEntityTypes(entityTypes, remoteEntityTypes, remoteTranslations), nil
+}
+
+func (applier *entityTypesApplier) ApplyEntityTypes(ctx context.Context) error {
+	changes, err := applier.PlanEntityTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The translations of added entity types are applied to the entity
+	// types created before them.
+	names := make(map[string]string)
+	for _, change := range changes {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		switch {
+		case change.LanguageCode != "":
+			if change.remoteEntityType.Name == "" {
+				change.remoteEntityType.Name = names[change.DisplayName]
+			}
+			if err = applier.updateEntityType(ctx, change); err != nil {
+				return fmt.Errorf("entity type %q in language %q: %v", change.DisplayName, change.LanguageCode, err)
+			}
+		case change.Action == ChangeActionAdd:
+			newEntityType, err := applier.entityTypesClient.CreateEntityType(ctx, change.entityType)
+			if err != nil {
+				return fmt.Errorf("create entity type: %v", err)
+			}
+			names[change.DisplayName] = newEntityType.Name
+		case change.Action == ChangeActionChange:
+			if err = applier.updateEntityType(ctx, change); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateEntityType updates only the changed fields and entities, in the
+// language of the change, so that the entity type and the intent parameters
+// that refer to it are kept.
+func (applier *entityTypesApplier) updateEntityType(ctx context.Context, change EntityTypeChange) error {
+	entityType := change.entityType
+	entityType.Name = change.remoteEntityType.Name
+	entityTypeID := path.Base(entityType.Name)
+
+	if len(change.Fields) > 0 {
+		_, err := applier.entityTypesClient.UpdateEntityType(ctx, entityType, "kind", "auto_expansion_mode", "enable_fuzzy_extraction")
+		if err != nil {
+			return fmt.Errorf("update entity type: %v", err)
+		}
+	}
+
+	actions := make(map[string]ChangeAction)
+	var deleteValues []string
+	for _, entityChange := range change.Entities {
+		actions[entityChange.Value] = entityChange.Action
+		if entityChange.Action == ChangeActionRemove {
+			deleteValues = append(deleteValues, entityChange.Value)
+		}
+	}
+
+	// The entities are created in the order of the file.
+	var createEntities, updateEntities []Entity
+	for _, entity := range entityType.Entities {
+		switch actions[entity.Value] {
+		case ChangeActionAdd:
+			createEntities = append(createEntities, entity)
+		case ChangeActionChange:
+			updateEntities = append(updateEntities, entity)
+		}
+	}
+
+	if len(deleteValues) > 0 {
+		op, err := applier.entityTypesClient.BatchDeleteEntities(ctx, entityTypeID, change.LanguageCode, deleteValues)
+		if err != nil {
+			return fmt.Errorf("delete entities: %v", err)
+		}
+		if err = WaitOperation(ctx, op, DefaultPollInterval, nil); err != nil {
+			return fmt.Errorf("wait for delete entities: %v", err)
+		}
+	}
+	if len(updateEntities) > 0 {
+		op, err := applier.entityTypesClient.BatchUpdateEntities(ctx, entityTypeID, change.LanguageCode, updateEntities)
+		if err != nil {
+			return fmt.Errorf("update entities: %v", err)
+		}
+		if err = WaitOperation(ctx, op, DefaultPollInterval, nil); err != nil {
+			return fmt.Errorf("wait for update entities: %v", err)
+		}
+	}
+	if len(createEntities) > 0 {
+		op, err := applier.entityTypesClient.BatchCreateEntities(ctx, entityTypeID, change.LanguageCode, createEntities)
+		if err != nil {
+			return fmt.Errorf("create entities: %v", err)
+		}
+		if err = WaitOperation(ctx, op, DefaultPollInterval, nil); err != nil {
+			return fmt.Errorf("wait for create entities: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (applier *entityTypesApplier) PruneEntityTypes(ctx context.Context) error {
+	changes, err := applier.PlanEntityTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	var deleteEntityTypes []EntityType
+	for _, change := range changes {
+		if change.Action == ChangeActionRemove {
+			deleteEntityTypes = append(deleteEntityTypes, change.remoteEntityType)
+		}
+	}
+
+	if len(deleteEntityTypes) == 0 {
+		return nil
+	}
+
+	op, err := applier.entityTypesClient.DeleteEntityTypes(ctx, deleteEntityTypes)
+	if err != nil {
+		return fmt.Errorf("delete entity types: %v", err)
+	}
+	if err = WaitOperation(ctx, op, DefaultPollInterval, nil); err != nil {
+		return fmt.Errorf("wait for delete entity types: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,454 @@
+package dialogflow_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow/dialogflowtest"
+)
+
+func newTestEntityTypesClient(t *testing.T, server *dialogflowtest.Server) *dialogflow.EntityTypesClient {
+	entityTypesClient, err := dialogflow.NewEntityTypesClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entityTypesClient
+}
+
+func serverEntityTypes(server *dialogflowtest.Server) map[string][]string {
+	entityTypes := make(map[string][]string)
+	for _, entityType := range server.EntityTypes() {
+		values := []string{}
+		for _, entity := range entityType.Entities {
+			values = append(values, entity.Value)
+		}
+		entityTypes[entityType.DisplayName] = values
+	}
+	return entityTypes
+}
+
+func TestImportEntityTypes(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	entityTypesClient := newTestEntityTypesClient(t, server)
+	defer entityTypesClient.Close()
+
+	importer := dialogflow.NewEntityTypesImporter(entityTypesClient, dialogflow.NewFileSource("../examples/entities.yaml"))
+	if err := importer.ImportEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"name":     {"@sys.person:person", "@sys.given-name:given-name", "John"},
+		"location": {"@sys.geo-country:geo-country", "@sys.geo-city:geo-city"},
+		"colour":   {"@sys.color:color", "Sky blue"},
+		"mood":     {"good", "bad"},
+	}
+
+	if entityTypes := serverEntityTypes(server); !reflect.DeepEqual(expected, entityTypes) {
+		t.Errorf("expected %v, got %v", expected, entityTypes)
+	}
+}
+
+func TestApplyEntityTypes(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	entityTypesClient := newTestEntityTypesClient(t, server)
+	defer entityTypesClient.Close()
+
+	source, remove := newTestSource(t, `
+entities:
+  - type: fruit
+    values:
+      - apple
+      - banana
+  - type: color
+    values:
+      - red
+`)
+	defer remove()
+
+	applier := dialogflow.NewEntityTypesApplier(entityTypesClient, source)
+	if err := applier.ApplyEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fruitName := server.EntityTypes()[0].Name
+
+	source, remove = newTestSource(t, `
+entities:
+  - type: fruit
+    values:
+      - apple
+      - cherry
+`)
+	defer remove()
+
+	applier = dialogflow.NewEntityTypesApplier(entityTypesClient, source)
+	if err := applier.ApplyEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := applier.PruneEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"fruit": {"apple", "cherry"},
+	}
+
+	if entityTypes := serverEntityTypes(server); !reflect.DeepEqual(expected, entityTypes) {
+		t.Errorf("expected %v, got %v", expected, entityTypes)
+	}
+
+	if name := server.EntityTypes()[0].Name; name != fruitName {
+		t.Errorf("expected entity type %q to be updated in place, got %q", fruitName, name)
+	}
+
+	changes, err := applier.PlanEntityTypes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes after apply, got %+v", changes)
+	}
+}
+
+func TestApplyEntityTypesSynonyms(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	entityTypesClient := newTestEntityTypesClient(t, server)
+	defer entityTypesClient.Close()
+
+	source, remove := newTestSource(t, `
+entities:
+  - type: fruit
+    values:
+      - value: apple
+        synonyms:
+          - apple
+          - pomme
+      - banana
+`)
+	defer remove()
+
+	applier := dialogflow.NewEntityTypesApplier(entityTypesClient, source)
+	if err := applier.ApplyEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	fruitName := server.EntityTypes()[0].Name
+
+	source, remove = newTestSource(t, `
+entities:
+  - type: fruit
+    values:
+      - value: apple
+        synonyms:
+          - apple
+          - malus
+      - banana
+`)
+	defer remove()
+
+	applier = dialogflow.NewEntityTypesApplier(entityTypesClient, source)
+	changes, err := applier.PlanEntityTypes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || len(changes[0].Entities) != 1 || changes[0].Entities[0].Action != dialogflow.ChangeActionChange {
+		t.Fatalf("expected only the apple entity to change, got %+v", changes)
+	}
+
+	if err = applier.ApplyEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	entityTypes := server.EntityTypes()
+	if len(entityTypes) != 1 {
+		t.Fatalf("expected 1 entity type, got %d", len(entityTypes))
+	}
+	if name := entityTypes[0].Name; name != fruitName {
+		t.Errorf("expected entity type %q to be updated in place, got %q", fruitName, name)
+	}
+
+	expected := map[string][]string{
+		"apple":  {"apple", "malus"},
+		"banana": {"banana"},
+	}
+	synonyms := make(map[string][]string)
+	for _, entity := range entityTypes[0].Entities {
+		synonyms[entity.Value] = entity.Synonyms
+	}
+	if !reflect.DeepEqual(expected, synonyms) {
+		t.Errorf("expected %v, got %v", expected, synonyms)
+	}
+}
+
+func TestImportCompositeEntityTypes(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	entityTypesClient := newTestEntityTypesClient(t, server)
+	defer entityTypesClient.Close()
+
+	source, remove := newTestSource(t, `
+entities:
+  - type: order
+    values:
+      - '@size:size @topping:topping pizza'
+      - '@sys.number:amount @size:size pizzas'
+  - type: size
+    values:
+      - small
+      - large
+  - type: topping
+    values:
+      - value: mushroom
+        synonyms:
+          - mushroom
+          - mushrooms
+`)
+	defer remove()
+
+	importer := dialogflow.NewEntityTypesImporter(entityTypesClient, source)
+	if err := importer.ImportEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, entityType := range server.EntityTypes() {
+		names = append(names, entityType.DisplayName)
+	}
+	if expected := []string{"size", "topping", "order"}; !reflect.DeepEqual(expected, names) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestImportCompositeEntityTypesUnknownReference(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	entityTypesClient := newTestEntityTypesClient(t, server)
+	defer entityTypesClient.Close()
+
+	source, remove := newTestSource(t, `
+entities:
+  - type: size
+    values:
+      - small
+  - type: order
+    values:
+      - '@size:size @crust:crust pizza'
+`)
+	defer remove()
+
+	importer := dialogflow.NewEntityTypesImporter(entityTypesClient, source)
+	if err := importer.ImportEntityTypes(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+	if len(server.EntityTypes()) != 0 {
+		t.Errorf("expected no entity types, got %d", len(server.EntityTypes()))
+	}
+}
+
+func TestImportEntityTypesLanguages(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	entityTypesClient := newTestEntityTypesClient(t, server)
+	defer entityTypesClient.Close()
+
+	source, remove := newTestSource(t, `
+entities:
+  - type: size
+    values:
+      - small
+      - large
+    languages:
+      de:
+        values:
+          - klein
+          - groß
+      nl:
+        values:
+          - klein
+          - groot
+`)
+	defer remove()
+
+	importer := dialogflow.NewEntityTypesImporter(entityTypesClient, source, dialogflow.WithLanguages("de"))
+	if err := importer.ImportEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"en": {"small", "large"},
+		"de": {"klein", "groß"},
+		"nl": nil,
+	}
+
+	for languageCode, values := range expected {
+		entityTypes := server.LocalizedEntityTypes(languageCode)
+		if len(entityTypes) != 1 {
+			t.Fatalf("%s: expected 1 entity type, got %d", languageCode, len(entityTypes))
+		}
+		var entities []string
+		for _, entity := range entityTypes[0].Entities {
+			entities = append(entities, entity.Value)
+		}
+		if !reflect.DeepEqual(values, entities) {
+			t.Errorf("%s: expected %v, got %v", languageCode, values, entities)
+		}
+	}
+}
+
+func TestApplyEntityTypesLanguages(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	entityTypesClient := newTestEntityTypesClient(t, server)
+	defer entityTypesClient.Close()
+
+	source, remove := newTestSource(t, `
+entities:
+  - type: size
+    values:
+      - small
+      - large
+    languages:
+      de:
+        values:
+          - klein
+          - gross
+`)
+	defer remove()
+
+	applier := dialogflow.NewEntityTypesApplier(entityTypesClient, source)
+	if err := applier.ApplyEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	source, remove = newTestSource(t, `
+entities:
+  - type: size
+    values:
+      - small
+      - large
+    languages:
+      de:
+        values:
+          - klein
+          - groß
+      nl:
+        values:
+          - klein
+          - groot
+`)
+	defer remove()
+
+	applier = dialogflow.NewEntityTypesApplier(entityTypesClient, source)
+	changes, err := applier.PlanEntityTypes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var languageCodes []string
+	for _, change := range changes {
+		languageCodes = append(languageCodes, change.LanguageCode)
+	}
+	if expected := []string{"de", "nl"}; !reflect.DeepEqual(expected, languageCodes) {
+		t.Fatalf("expected changes in %v, got %+v", expected, changes)
+	}
+
+	if err = applier.ApplyEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{
+		"en": {"small", "large"},
+		"de": {"klein", "groß"},
+		"nl": {"klein", "groot"},
+	}
+
+	for languageCode, values := range expected {
+		entityTypes := server.LocalizedEntityTypes(languageCode)
+		if len(entityTypes) != 1 {
+			t.Fatalf("%s: expected 1 entity type, got %d", languageCode, len(entityTypes))
+		}
+		var entities []string
+		for _, entity := range entityTypes[0].Entities {
+			entities = append(entities, entity.Value)
+		}
+		if !reflect.DeepEqual(values, entities) {
+			t.Errorf("%s: expected %v, got %v", languageCode, values, entities)
+		}
+	}
+
+	if changes, err = applier.PlanEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes after apply, got %+v", changes)
+	}
+}
+
+func TestExportEntityTypesLanguages(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	entityTypesClient := newTestEntityTypesClient(t, server)
+	defer entityTypesClient.Close()
+
+	source, remove := newTestSource(t, `
+entities:
+  - type: size
+    values:
+      - value: small
+        synonyms:
+          - small
+          - little
+      - large
+    languages:
+      de:
+        values:
+          - value: klein
+            synonyms:
+              - klein
+              - winzig
+          - groß
+  - type: topping
+    values:
+      - cheese
+`)
+	defer remove()
+
+	if err := dialogflow.NewEntityTypesImporter(entityTypesClient, source).ImportEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := dialogflow.NewEntityTypesExporter(entityTypesClient, &buf, "de").ExportEntityTypes(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The exported entity types and translations are the imported ones.
+	exported, remove := newTestSource(t, buf.String())
+	defer remove()
+
+	changes, err := dialogflow.NewEntityTypesApplier(entityTypesClient, exported).PlanEntityTypes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v in\n%s", changes, buf.String())
+	}
+	if !strings.Contains(buf.String(), "winzig") {
+		t.Errorf("expected the German synonyms in\n%s", buf.String())
+	}
+}
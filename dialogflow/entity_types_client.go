@@ -11,15 +11,29 @@ import (
 	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
 )
 
+// EntityTypesAPI is the interface of the entity types client, so that code
+// using it can be tested with a fake.
+type EntityTypesAPI interface {
+	ListEntityTypes(ctx context.Context, languageCode string) ([]EntityType, error)
+	GetEntityType(ctx context.Context, entityTypeID string) (EntityType, error)
+	CreateEntityType(ctx context.Context, entityType EntityType) (EntityType, error)
+	UpdateEntityType(ctx context.Context, entityType EntityType, updateMask ...string) (EntityType, error)
+	DeleteEntityType(ctx context.Context, entityTypeID string) error
+	DeleteEntityTypes(ctx context.Context, entityTypes []EntityType) (Operation, error)
+	BatchCreateEntities(ctx context.Context, entityTypeID, languageCode string, entities []Entity) (Operation, error)
+	BatchUpdateEntities(ctx context.Context, entityTypeID, languageCode string, entities []Entity, updateMask ...string) (Operation, error)
+	BatchDeleteEntities(ctx context.Context, entityTypeID, languageCode string, values []string) (Operation, error)
+}
+
+var _ EntityTypesAPI = (*EntityTypesClient)(nil)
+
 type EntityTypesClient struct {
 	projectID         string
 	entityTypesClient *dialogflow.EntityTypesClient
 }
 
-func NewEntityTypesClient(projectID, credentialsFile string) (*EntityTypesClient, error) {
-	ctx := context.Background()
-
-	entityTypesClient, err := dialogflow.NewEntityTypesClient(ctx, option.WithCredentialsFile(credentialsFile))
+func NewEntityTypesClient(ctx context.Context, projectID string, opts ...option.ClientOption) (*EntityTypesClient, error) {
+	entityTypesClient, err := dialogflow.NewEntityTypesClient(ctx, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -30,11 +44,14 @@ func NewEntityTypesClient(projectID, credentialsFile string) (*EntityTypesClient
 	}, nil
 }
 
-func (client *EntityTypesClient) ListEntityTypes() ([]EntityType, error) {
+// ListEntityTypes lists the entity types with the entities in the language,
+// or in the default language of the agent if the language code is empty.
+func (client *EntityTypesClient) ListEntityTypes(ctx context.Context, languageCode string) ([]EntityType, error) {
 	iter := client.entityTypesClient.ListEntityTypes(
-		context.Background(),
+		ctx,
 		&dialogflowpb.ListEntityTypesRequest{
-			Parent: fmt.Sprintf("projects/%s/agent", client.projectID),
+			Parent:       fmt.Sprintf("projects/%s/agent", client.projectID),
+			LanguageCode: languageCode,
 		},
 	)
 
@@ -47,15 +64,17 @@ func (client *EntityTypesClient) ListEntityTypes() ([]EntityType, error) {
 		if err != nil {
 			return nil, err
 		}
-		entityTypes = append(entityTypes, dialogflowEntityTypeToEntityType(entityType))
+		e := dialogflowEntityTypeToEntityType(entityType)
+		e.LanguageCode = languageCode
+		entityTypes = append(entityTypes, e)
 	}
 
 	return entityTypes, nil
 }
 
-func (client *EntityTypesClient) GetEntityType(entityTypeID string) (EntityType, error) {
+func (client *EntityTypesClient) GetEntityType(ctx context.Context, entityTypeID string) (EntityType, error) {
 	entityType, err := client.entityTypesClient.GetEntityType(
-		context.Background(),
+		ctx,
 		&dialogflowpb.GetEntityTypeRequest{
 			Name: fmt.Sprintf("projects/%s/agent/entityTypes/%s", client.projectID, entityTypeID),
 		},
@@ -67,53 +86,63 @@ func (client *EntityTypesClient) GetEntityType(entityTypeID string) (EntityType,
 	return dialogflowEntityTypeToEntityType(entityType), nil
 }
 
-func (client *EntityTypesClient) CreateEntityType(entityType EntityType) (EntityType, error) {
+func (client *EntityTypesClient) CreateEntityType(ctx context.Context, entityType EntityType) (EntityType, error) {
 	if entityType.DisplayName == "" {
 		return EntityType{}, errors.New("display name is empty")
 	}
 
-	kind := dialogflowpb.EntityType_KIND_LIST
-	if val, ok := dialogflowpb.EntityType_Kind_value[entityType.Kind]; ok {
-		kind = dialogflowpb.EntityType_Kind(val)
-	}
-	autoExpansionMode := dialogflowpb.EntityType_AUTO_EXPANSION_MODE_UNSPECIFIED
-	if val, ok := dialogflowpb.EntityType_AutoExpansionMode_value[entityType.AutoExpansionMode]; ok {
-		autoExpansionMode = dialogflowpb.EntityType_AutoExpansionMode(val)
+	dialogflowEntityType, err := client.entityTypesClient.CreateEntityType(
+		ctx,
+		&dialogflowpb.CreateEntityTypeRequest{
+			Parent:       fmt.Sprintf("projects/%s/agent", client.projectID),
+			EntityType:   toDialogflowEntityType(entityType),
+			LanguageCode: entityType.LanguageCode,
+		},
+	)
+	if err != nil {
+		return EntityType{}, err
 	}
 
-	var entities []*dialogflowpb.EntityType_Entity
-	for _, entity := range entityType.Entities {
-		entities = append(entities, &dialogflowpb.EntityType_Entity{
-			Value:    entity.Value,
-			Synonyms: entity.Synonyms,
-		})
+	newEntityType := dialogflowEntityTypeToEntityType(dialogflowEntityType)
+	newEntityType.LanguageCode = entityType.LanguageCode
+
+	return newEntityType, nil
+}
+
+func (client *EntityTypesClient) UpdateEntityType(ctx context.Context, entityType EntityType, updateMask ...string) (EntityType, error) {
+	if entityType.Name == "" {
+		return EntityType{}, errors.New("entity type name is empty")
+	}
+	if entityType.DisplayName == "" {
+		return EntityType{}, errors.New("display name is empty")
 	}
 
-	dialogflowEntityType, err := client.entityTypesClient.CreateEntityType(
-		context.Background(),
-		&dialogflowpb.CreateEntityTypeRequest{
-			Parent: fmt.Sprintf("projects/%s/agent", client.projectID),
-			EntityType: &dialogflowpb.EntityType{
-				DisplayName:           entityType.DisplayName,
-				Kind:                  kind,
-				AutoExpansionMode:     autoExpansionMode,
-				Entities:              entities,
-				EnableFuzzyExtraction: entityType.EnableFuzzyExtraction,
-			},
+	dialogflowEntityType := toDialogflowEntityType(entityType)
+	dialogflowEntityType.Name = entityType.Name
+
+	dialogflowEntityType, err := client.entityTypesClient.UpdateEntityType(
+		ctx,
+		&dialogflowpb.UpdateEntityTypeRequest{
+			EntityType:   dialogflowEntityType,
+			LanguageCode: entityType.LanguageCode,
+			UpdateMask:   toFieldMask(updateMask),
 		},
 	)
 	if err != nil {
 		return EntityType{}, err
 	}
 
-	return dialogflowEntityTypeToEntityType(dialogflowEntityType), nil
+	updatedEntityType := dialogflowEntityTypeToEntityType(dialogflowEntityType)
+	updatedEntityType.LanguageCode = entityType.LanguageCode
+
+	return updatedEntityType, nil
 }
 
-func (client *EntityTypesClient) DeleteEntityType(entityTypeID string) error {
+func (client *EntityTypesClient) DeleteEntityType(ctx context.Context, entityTypeID string) error {
 	if entityTypeID == "" {
 		return errors.New("missing entity type id")
 	}
-	err := client.entityTypesClient.DeleteEntityType(context.Background(), &dialogflowpb.DeleteEntityTypeRequest{
+	err := client.entityTypesClient.DeleteEntityType(ctx, &dialogflowpb.DeleteEntityTypeRequest{
 		Name: fmt.Sprintf("projects/%s/agent/entityTypes/%s", client.projectID, entityTypeID),
 	})
 	if err != nil {
@@ -122,22 +151,86 @@ func (client *EntityTypesClient) DeleteEntityType(entityTypeID string) error {
 	return nil
 }
 
-func (client *EntityTypesClient) DeleteEntityTypes(entityTypes []EntityType) error {
+// DeleteEntityTypes starts deleting the entity types and returns the
+// operation, which the caller can wait on with WaitOperation.
+func (client *EntityTypesClient) DeleteEntityTypes(ctx context.Context, entityTypes []EntityType) (Operation, error) {
 	var entityTypeNames []string
 	for _, entityType := range entityTypes {
 		entityTypeNames = append(entityTypeNames, entityType.Name)
 	}
-	_, err := client.entityTypesClient.BatchDeleteEntityTypes(
-		context.Background(),
+	op, err := client.entityTypesClient.BatchDeleteEntityTypes(
+		ctx,
 		&dialogflowpb.BatchDeleteEntityTypesRequest{
 			Parent:          fmt.Sprintf("projects/%s/agent", client.projectID),
 			EntityTypeNames: entityTypeNames,
 		},
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return batchDeleteEntityTypesOperation{op}, nil
+}
+
+// BatchCreateEntities starts creating the entities in the entity type and
+// returns the operation, which the caller can wait on with WaitOperation.
+func (client *EntityTypesClient) BatchCreateEntities(ctx context.Context, entityTypeID, languageCode string, entities []Entity) (Operation, error) {
+	if entityTypeID == "" {
+		return nil, errors.New("missing entity type id")
+	}
+	op, err := client.entityTypesClient.BatchCreateEntities(
+		ctx,
+		&dialogflowpb.BatchCreateEntitiesRequest{
+			Parent:       fmt.Sprintf("projects/%s/agent/entityTypes/%s", client.projectID, entityTypeID),
+			Entities:     toDialogflowEntities(entities),
+			LanguageCode: languageCode,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return batchCreateEntitiesOperation{op}, nil
+}
+
+// BatchUpdateEntities starts updating the entities in the entity type and
+// returns the operation, which the caller can wait on with WaitOperation.
+func (client *EntityTypesClient) BatchUpdateEntities(ctx context.Context, entityTypeID, languageCode string, entities []Entity, updateMask ...string) (Operation, error) {
+	if entityTypeID == "" {
+		return nil, errors.New("missing entity type id")
+	}
+	op, err := client.entityTypesClient.BatchUpdateEntities(
+		ctx,
+		&dialogflowpb.BatchUpdateEntitiesRequest{
+			Parent:       fmt.Sprintf("projects/%s/agent/entityTypes/%s", client.projectID, entityTypeID),
+			Entities:     toDialogflowEntities(entities),
+			LanguageCode: languageCode,
+			UpdateMask:   toFieldMask(updateMask),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return batchUpdateEntitiesOperation{op}, nil
+}
+
+// BatchDeleteEntities starts deleting the entities with the given values from
+// the entity type and returns the operation, which the caller can wait on with
+// WaitOperation.
+func (client *EntityTypesClient) BatchDeleteEntities(ctx context.Context, entityTypeID, languageCode string, values []string) (Operation, error) {
+	if entityTypeID == "" {
+		return nil, errors.New("missing entity type id")
+	}
+	op, err := client.entityTypesClient.BatchDeleteEntities(
+		ctx,
+		&dialogflowpb.BatchDeleteEntitiesRequest{
+			Parent:       fmt.Sprintf("projects/%s/agent/entityTypes/%s", client.projectID, entityTypeID),
+			EntityValues: values,
+			LanguageCode: languageCode,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return batchDeleteEntitiesOperation{op}, nil
 }
 
 func (client *EntityTypesClient) Close() error {
@@ -161,3 +254,33 @@ func dialogflowEntityTypeToEntityType(dialogflowEntityType *dialogflowpb.EntityT
 		EnableFuzzyExtraction: dialogflowEntityType.EnableFuzzyExtraction,
 	}
 }
+
+func toDialogflowEntityType(entityType EntityType) *dialogflowpb.EntityType {
+	kind := dialogflowpb.EntityType_KIND_LIST
+	if val, ok := dialogflowpb.EntityType_Kind_value[entityType.Kind]; ok {
+		kind = dialogflowpb.EntityType_Kind(val)
+	}
+	autoExpansionMode := dialogflowpb.EntityType_AUTO_EXPANSION_MODE_UNSPECIFIED
+	if val, ok := dialogflowpb.EntityType_AutoExpansionMode_value[entityType.AutoExpansionMode]; ok {
+		autoExpansionMode = dialogflowpb.EntityType_AutoExpansionMode(val)
+	}
+
+	return &dialogflowpb.EntityType{
+		DisplayName:           entityType.DisplayName,
+		Kind:                  kind,
+		AutoExpansionMode:     autoExpansionMode,
+		Entities:              toDialogflowEntities(entityType.Entities),
+		EnableFuzzyExtraction: entityType.EnableFuzzyExtraction,
+	}
+}
+
+func toDialogflowEntities(entities []Entity) []*dialogflowpb.EntityType_Entity {
+	var dialogflowEntities []*dialogflowpb.EntityType_Entity
+	for _, entity := range entities {
+		dialogflowEntities = append(dialogflowEntities, &dialogflowpb.EntityType_Entity{
+			Value:    entity.Value,
+			Synonyms: entity.Synonyms,
+		})
+	}
+	return dialogflowEntities
+}
@@ -0,0 +1,143 @@
+package dialogflow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+)
+
+type EntityTypesExporter interface {
+	ExportEntityTypes(ctx context.Context) error
+}
+
+type entityTypesExporter struct {
+	entityTypesClient EntityTypesAPI
+	writer            io.Writer
+	languageCodes     []string
+}
+
+// NewEntityTypesExporter returns an exporter of the entity types in the
+// default language of the agent, with their translations in the languages.
+func NewEntityTypesExporter(entityTypesClient EntityTypesAPI, writer io.Writer, languageCodes ...string) EntityTypesExporter {
+	return &entityTypesExporter{
+		entityTypesClient: entityTypesClient,
+		writer:            writer,
+		languageCodes:     languageCodes,
+	}
+}
+
+func (exporter *entityTypesExporter) ExportEntityTypes(ctx context.Context) error {
+	entityTypes, err := exporter.entityTypesClient.ListEntityTypes(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list entity types: %v", err)
+	}
+
+	codes, err := languageCodes(exporter.languageCodes)
+	if err != nil {
+		return err
+	}
+	for _, languageCode := range codes {
+		translations, err := exporter.entityTypesClient.ListEntityTypes(ctx, languageCode)
+		if err != nil {
+			return fmt.Errorf("list entity types in language %q: %v", languageCode, err)
+		}
+		translated := make(map[string]EntityType)
+		for _, translation := range translations {
+			translated[translation.Name] = translation
+		}
+		for i, entityType := range entityTypes {
+			if translation, ok := translated[entityType.Name]; ok {
+				entityTypes[i].Translations = append(entityType.Translations, translation)
+			}
+		}
+	}
+
+	data, err := writeEntityTypes(entityTypes)
+	if err != nil {
+		return fmt.Errorf("write entity types: %v", err)
+	}
+
+	if _, err = exporter.writer.Write(data); err != nil {
+		return fmt.Errorf("write data: %v", err)
+	}
+
+	return nil
+}
+
+func entityTypeToEntityTypeData(entityType EntityType) entityTypeData {
+	data := entityTypeData{
+		EntityType:    entityType.DisplayName,
+		AutoExpansion: entityType.AutoExpansionMode == dialogflowpb.EntityType_AUTO_EXPANSION_MODE_DEFAULT.String(),
+		Fuzzy:         entityType.EnableFuzzyExtraction,
+	}
+
+	var hasSynonyms bool
+	data.Values, hasSynonyms = entitiesToEntityData(entityType.Entities)
+
+	for _, translation := range entityType.Translations {
+		values, _ := entitiesToEntityData(translation.Entities)
+		if len(values) == 0 {
+			continue
+		}
+		if data.Languages == nil {
+			data.Languages = make(map[string]entityTypeLanguageData)
+		}
+		data.Languages[translation.LanguageCode] = entityTypeLanguageData{Values: values}
+	}
+
+	// The kind is left out when it is the kind the entity type gets when
+	// it is read.
+	kind := dialogflowpb.EntityType_KIND_LIST.String()
+	if hasSynonyms {
+		kind = dialogflowpb.EntityType_KIND_MAP.String()
+	}
+	if entityType.Kind != kind {
+		for name, k := range entityKinds {
+			if k.String() == entityType.Kind {
+				data.Kind = name
+			}
+		}
+	}
+
+	return data
+}
+
+// entitiesToEntityData returns the entities as values, and whether any of them
+// has synonyms.
+func entitiesToEntityData(entities []Entity) ([]entityData, bool) {
+	var (
+		values      []entityData
+		hasSynonyms bool
+	)
+	for _, entity := range entities {
+		value := entityData{Value: entity.Value}
+		// Dialogflow sets the synonyms of entities without synonyms to
+		// their value.
+		if len(entity.Synonyms) > 0 && !(len(entity.Synonyms) == 1 && entity.Synonyms[0] == entity.Value) {
+			value.Synonyms = entity.Synonyms
+			hasSynonyms = true
+		}
+		values = append(values, value)
+	}
+	return values, hasSynonyms
+}
+
+// writeEntityTypes writes the entity types in the format read by
+// readEntityTypes.
+func writeEntityTypes(entityTypes []EntityType) ([]byte, error) {
+	entityTypes = append([]EntityType(nil), entityTypes...)
+	sort.SliceStable(entityTypes, func(i, j int) bool {
+		return entityTypes[i].DisplayName < entityTypes[j].DisplayName
+	})
+
+	var data entityTypesData
+	for _, entityType := range entityTypes {
+		data.EntityTypes = append(data.EntityTypes, entityTypeToEntityTypeData(entityType))
+	}
+
+	return yaml.Marshal(data)
+}
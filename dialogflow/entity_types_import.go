@@ -1,73 +1,211 @@
 package dialogflow
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 
-	"github.com/ghodss/yaml"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
 )
 
 type EntityTypesImporter interface {
-	ImportEntityTypes() error
+	ImportEntityTypes(ctx context.Context) error
 }
 
 type entityTypesImporter struct {
-	entityTypesClient *EntityTypesClient
+	entityTypesClient EntityTypesAPI
 	source            Source
+	opts              importerOptions
 }
 
-func NewEntityTypesImporter(entityTypesClient *EntityTypesClient, source Source) EntityTypesImporter {
+func NewEntityTypesImporter(entityTypesClient EntityTypesAPI, source Source, opts ...ImporterOption) EntityTypesImporter {
 	return &entityTypesImporter{
 		entityTypesClient: entityTypesClient,
 		source:            source,
+		opts:              newImporterOptions(opts),
 	}
 }
 
-func (importer *entityTypesImporter) ImportEntityTypes() error {
-	data, err := ioutil.ReadAll(importer.source)
+func (importer *entityTypesImporter) ImportEntityTypes(ctx context.Context) error {
+	data, err := importer.source.ReadAll(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to read data: %v", err)
 	}
 
 	entityTypes, err := readEntityTypes(data)
 	if err != nil {
-		return fmt.Errorf("read entity types: %v", err)
+		return fmt.Errorf("read entity types: %v", sourceError(importer.source, err))
+	}
+
+	if err = checkLimits(nil, entityTypes, importer.opts.limits); err != nil {
+		return err
+	}
+
+	if err = importer.checkEntityReferences(ctx, entityTypes); err != nil {
+		return err
 	}
 
 	for _, entityType := range entityTypes {
-		if _, err := importer.entityTypesClient.CreateEntityType(entityType); err != nil {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		newEntityType, err := importer.entityTypesClient.CreateEntityType(ctx, entityType)
+		if err != nil {
 			return fmt.Errorf("create entity type: %v", err)
 		}
+		for _, translation := range entityType.Translations {
+			if !importer.opts.importLanguage(translation.LanguageCode) {
+				continue
+			}
+			newEntityType.LanguageCode = translation.LanguageCode
+			newEntityType.Entities = translation.Entities
+			if _, err = importer.entityTypesClient.UpdateEntityType(ctx, newEntityType, "entities"); err != nil {
+				return fmt.Errorf("update entity type %q in language %q: %v", entityType.DisplayName, translation.LanguageCode, err)
+			}
+		}
 	}
 
 	return nil
 }
 
-func readEntityTypes(dat []byte) ([]EntityType, error) {
-	var data struct {
-		EntityTypes []struct {
-			EntityType string   `json:"type"`
-			Values     []string `json:"values"`
-		} `json:"entities"`
+// checkEntityReferences checks the entity types referenced by composite entity
+// types, listing the remote entity types only if an entity type references an
+// entity type that is not imported.
+func (importer *entityTypesImporter) checkEntityReferences(ctx context.Context, entityTypes []EntityType) error {
+	if checkEntityReferences(entityTypes, nil) == nil {
+		return nil
+	}
+
+	remoteEntityTypes, err := importer.entityTypesClient.ListEntityTypes(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list entity types: %v", err)
+	}
+
+	return checkEntityReferences(entityTypes, remoteEntityTypes)
+}
+
+type entityTypeData struct {
+	EntityType    string       `json:"type"`
+	Kind          string       `json:"kind,omitempty" enum:"map,list,regexp"`
+	AutoExpansion bool         `json:"autoExpansion,omitempty"`
+	Fuzzy         bool         `json:"fuzzy,omitempty"`
+	Values        []entityData `json:"values"`
+
+	Languages map[string]entityTypeLanguageData `json:"languages,omitempty"`
+}
+
+// entityData is an entity value with its synonyms, written as the value when
+// it has no other synonyms.
+type entityData struct {
+	Value    string   `json:"value"`
+	Synonyms []string `json:"synonyms,omitempty"`
+}
+
+func (data *entityData) UnmarshalJSON(b []byte) error {
+	var value string
+	if err := json.Unmarshal(b, &value); err == nil {
+		*data = entityData{Value: value}
+		return nil
 	}
+	type plain entityData
+	return json.Unmarshal(b, (*plain)(data))
+}
 
-	if err := yaml.Unmarshal(dat, &data); err != nil {
-		return nil, fmt.Errorf("unmarshal data: %v", err)
+func (data entityData) MarshalJSON() ([]byte, error) {
+	if len(data.Synonyms) == 0 {
+		return json.Marshal(data.Value)
+	}
+	type plain entityData
+	return json.Marshal(plain(data))
+}
+
+var entityKinds = map[string]dialogflowpb.EntityType_Kind{
+	"map":    dialogflowpb.EntityType_KIND_MAP,
+	"list":   dialogflowpb.EntityType_KIND_LIST,
+	"regexp": dialogflowpb.EntityType_KIND_REGEXP,
+}
+
+// entityTypesData is the YAML format of entity types.
+type entityTypesData struct {
+	EntityTypes []entityTypeData `json:"entities"`
+}
+
+func readEntityTypes(dat []byte) ([]EntityType, error) {
+	var data entityTypesData
+	if err := decodeYAML(dat, &data); err != nil {
+		return nil, err
 	}
 
 	var entityTypes []EntityType
-	for _, entityType := range data.EntityTypes {
-		var entities []Entity
-		for _, val := range entityType.Values {
-			entities = append(entities, Entity{
-				Value: val,
-			})
+	for _, data := range data.EntityTypes {
+		entityType, err := entityTypeDataToEntityType(data)
+		if err != nil {
+			return nil, fmt.Errorf("entity type %q: %v", data.EntityType, err)
+		}
+		entityTypes = append(entityTypes, entityType)
+	}
+
+	// Composite entity types can only be created after the entity types
+	// they reference.
+	return sortEntityTypes(entityTypes)
+}
+
+func entityTypeDataToEntityType(data entityTypeData) (EntityType, error) {
+	var (
+		entities    []Entity
+		hasSynonyms bool
+	)
+	for _, val := range data.Values {
+		if val.Value == "" {
+			return EntityType{}, errors.New("entity value is empty")
 		}
-		entityTypes = append(entityTypes, EntityType{
-			DisplayName: entityType.EntityType,
-			Entities:    entities,
+		if len(val.Synonyms) > 0 && !(len(val.Synonyms) == 1 && val.Synonyms[0] == val.Value) {
+			hasSynonyms = true
+		}
+		entities = append(entities, Entity{
+			Value:    val.Value,
+			Synonyms: val.Synonyms,
 		})
 	}
 
-	return entityTypes, nil
+	// Entity types with synonyms are map entity types, unless they have a
+	// kind.
+	kind := dialogflowpb.EntityType_KIND_LIST
+	if hasSynonyms {
+		kind = dialogflowpb.EntityType_KIND_MAP
+	}
+	if data.Kind != "" {
+		var ok bool
+		if kind, ok = entityKinds[data.Kind]; !ok {
+			return EntityType{}, fmt.Errorf("unknown kind %q, must be map, list or regexp", data.Kind)
+		}
+		if hasSynonyms && kind != dialogflowpb.EntityType_KIND_MAP {
+			return EntityType{}, fmt.Errorf("only map entity types have synonyms")
+		}
+	}
+
+	autoExpansionMode := dialogflowpb.EntityType_AUTO_EXPANSION_MODE_UNSPECIFIED
+	if data.AutoExpansion {
+		autoExpansionMode = dialogflowpb.EntityType_AUTO_EXPANSION_MODE_DEFAULT
+	}
+
+	entityType := EntityType{
+		DisplayName:           data.EntityType,
+		Kind:                  kind.String(),
+		AutoExpansionMode:     autoExpansionMode.String(),
+		Entities:              entities,
+		EnableFuzzyExtraction: data.Fuzzy,
+	}
+	if err := checkEntityTypeValues(entityType); err != nil {
+		return EntityType{}, err
+	}
+
+	translations, err := entityTypeTranslations(entityType, data.Languages)
+	if err != nil {
+		return EntityType{}, err
+	}
+	entityType.Translations = translations
+
+	return entityType, nil
 }
@@ -0,0 +1,114 @@
+package dialogflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadEntityTypes(t *testing.T) {
+	data := []byte(`
+entities:
+  - type: colour
+    values:
+      - red
+      - blue
+  - type: size
+    autoExpansion: true
+    fuzzy: true
+    values:
+      - small
+      - value: large
+        synonyms:
+          - large
+          - big
+  - type: zip
+    kind: regexp
+    values:
+      - '[0-9]{4} ?[A-Z]{2}'
+`)
+
+	entityTypes, err := readEntityTypes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []EntityType{
+		{
+			DisplayName:       "colour",
+			Kind:              "KIND_LIST",
+			AutoExpansionMode: "AUTO_EXPANSION_MODE_UNSPECIFIED",
+			Entities:          []Entity{{Value: "red"}, {Value: "blue"}},
+		},
+		{
+			DisplayName:           "size",
+			Kind:                  "KIND_MAP",
+			AutoExpansionMode:     "AUTO_EXPANSION_MODE_DEFAULT",
+			Entities:              []Entity{{Value: "small"}, {Value: "large", Synonyms: []string{"large", "big"}}},
+			EnableFuzzyExtraction: true,
+		},
+		{
+			DisplayName:       "zip",
+			Kind:              "KIND_REGEXP",
+			AutoExpansionMode: "AUTO_EXPANSION_MODE_UNSPECIFIED",
+			Entities:          []Entity{{Value: "[0-9]{4} ?[A-Z]{2}"}},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, entityTypes) {
+		t.Errorf("expected %+v, got %+v", expected, entityTypes)
+	}
+}
+
+func TestReadEntityTypesInvalid(t *testing.T) {
+	tests := []string{
+		"{type: size, kind: set, values: [small]}",
+		"{type: size, kind: list, values: [{value: large, synonyms: [big]}]}",
+		"{type: size, values: [{synonyms: [big]}]}",
+	}
+
+	for _, test := range tests {
+		if _, err := readEntityTypes([]byte("entities:\n  - " + test + "\n")); err == nil {
+			t.Errorf("expected error for entity type %s", test)
+		}
+	}
+}
+
+func TestReadEntityTypesComposite(t *testing.T) {
+	tests := []struct {
+		data string
+		ok   bool
+	}{
+		{data: "{type: name, values: ['@sys.given-name:given-name @sys.last-name:last-name']}", ok: true},
+		{data: "{type: email, values: ['me@example.com']}", ok: true},
+		{data: "{type: name, values: ['@sys.given-name']}"},
+		{data: "{type: name, kind: map, values: ['@sys.given-name:given-name']}"},
+		{data: "{type: zip, kind: regexp, values: ['[0-9]{4}']}", ok: true},
+		{data: "{type: zip, kind: regexp, values: ['[0-9']}"},
+	}
+
+	for _, test := range tests {
+		_, err := readEntityTypes([]byte("entities:\n  - " + test.data + "\n"))
+		if test.ok && err != nil {
+			t.Errorf("%s: %v", test.data, err)
+		}
+		if !test.ok && err == nil {
+			t.Errorf("%s: expected error", test.data)
+		}
+	}
+}
+
+func TestReadEntityTypesCycle(t *testing.T) {
+	data := []byte(`
+entities:
+  - type: a
+    values:
+      - '@b:b'
+  - type: b
+    values:
+      - '@a:a'
+`)
+
+	if _, err := readEntityTypes(data); err == nil {
+		t.Error("expected error")
+	}
+}
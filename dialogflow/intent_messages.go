@@ -0,0 +1,288 @@
+package dialogflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+)
+
+// messageData is a response of an intent in the YAML format. Exactly one kind
+// of response is set, optionally for a platform such as telegram, slack,
+// facebook or google.
+type messageData struct {
+	Platform        string                 `json:"platform,omitempty"`
+	Text            []string               `json:"text,omitempty"`
+	Image           *imageData             `json:"image,omitempty"`
+	QuickReplies    *quickRepliesData      `json:"quickReplies,omitempty"`
+	Card            *cardData              `json:"card,omitempty"`
+	Payload         map[string]interface{} `json:"payload,omitempty"`
+	SimpleResponses []simpleResponseData   `json:"simpleResponses,omitempty"`
+	BasicCard       *basicCardData         `json:"basicCard,omitempty"`
+	Suggestions     []string               `json:"suggestions,omitempty"`
+}
+
+type imageData struct {
+	URL               string `json:"url"`
+	AccessibilityText string `json:"accessibilityText,omitempty"`
+}
+
+type quickRepliesData struct {
+	Title   string   `json:"title,omitempty"`
+	Replies []string `json:"replies"`
+}
+
+type cardData struct {
+	Title    string           `json:"title,omitempty"`
+	Subtitle string           `json:"subtitle,omitempty"`
+	Image    string           `json:"image,omitempty"`
+	Buttons  []cardButtonData `json:"buttons,omitempty"`
+}
+
+type cardButtonData struct {
+	Text     string `json:"text"`
+	Postback string `json:"postback,omitempty"`
+}
+
+type simpleResponseData struct {
+	TextToSpeech string `json:"textToSpeech,omitempty"`
+	SSML         string `json:"ssml,omitempty"`
+	DisplayText  string `json:"displayText,omitempty"`
+}
+
+type basicCardData struct {
+	Title    string                `json:"title,omitempty"`
+	Subtitle string                `json:"subtitle,omitempty"`
+	Text     string                `json:"text,omitempty"`
+	Image    *imageData            `json:"image,omitempty"`
+	Buttons  []basicCardButtonData `json:"buttons,omitempty"`
+}
+
+type basicCardButtonData struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+func messageDataToMessage(data messageData) (Message, error) {
+	platform, err := parsePlatform(data.Platform)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var kinds int
+	for _, ok := range []bool{
+		data.Text != nil,
+		data.Image != nil,
+		data.QuickReplies != nil,
+		data.Card != nil,
+		data.Payload != nil,
+		data.SimpleResponses != nil,
+		data.BasicCard != nil,
+		data.Suggestions != nil,
+	} {
+		if ok {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return Message{}, fmt.Errorf("message must have exactly one of text, image, quickReplies, card, payload, simpleResponses, basicCard or suggestions")
+	}
+
+	message := Message{
+		Text:        data.Text,
+		Image:       imageDataToImage(data.Image),
+		Payload:     data.Payload,
+		Suggestions: data.Suggestions,
+		Platform:    platform,
+	}
+	if data.QuickReplies != nil {
+		message.QuickReplies = &QuickReplies{
+			Title:        data.QuickReplies.Title,
+			QuickReplies: data.QuickReplies.Replies,
+		}
+	}
+	if data.Card != nil {
+		message.Card = &Card{
+			Title:    data.Card.Title,
+			Subtitle: data.Card.Subtitle,
+			ImageURI: data.Card.Image,
+		}
+		for _, b := range data.Card.Buttons {
+			message.Card.Buttons = append(message.Card.Buttons, CardButton{Text: b.Text, Postback: b.Postback})
+		}
+	}
+	if data.SimpleResponses != nil {
+		message.SimpleResponses = []SimpleResponse{}
+		for _, r := range data.SimpleResponses {
+			if r.TextToSpeech != "" && r.SSML != "" {
+				return Message{}, fmt.Errorf("simple response must have either textToSpeech or ssml")
+			}
+			message.SimpleResponses = append(message.SimpleResponses, SimpleResponse{
+				TextToSpeech: r.TextToSpeech,
+				SSML:         r.SSML,
+				DisplayText:  r.DisplayText,
+			})
+		}
+	}
+	if data.BasicCard != nil {
+		message.BasicCard = &BasicCard{
+			Title:         data.BasicCard.Title,
+			Subtitle:      data.BasicCard.Subtitle,
+			FormattedText: data.BasicCard.Text,
+			Image:         imageDataToImage(data.BasicCard.Image),
+		}
+		for _, b := range data.BasicCard.Buttons {
+			message.BasicCard.Buttons = append(message.BasicCard.Buttons, BasicCardButton{Title: b.Title, URI: b.URL})
+		}
+	}
+
+	return message, nil
+}
+
+func imageDataToImage(data *imageData) *Image {
+	if data == nil {
+		return nil
+	}
+	return &Image{ImageURI: data.URL, AccessibilityText: data.AccessibilityText}
+}
+
+func messageToMessageData(message Message) messageData {
+	data := messageData{
+		Platform:    formatPlatform(message.Platform),
+		Image:       imageToImageData(message.Image),
+		Payload:     message.Payload,
+		Suggestions: message.Suggestions,
+	}
+	if message.QuickReplies != nil {
+		data.QuickReplies = &quickRepliesData{
+			Title:   message.QuickReplies.Title,
+			Replies: message.QuickReplies.QuickReplies,
+		}
+	}
+	if message.Card != nil {
+		data.Card = &cardData{
+			Title:    message.Card.Title,
+			Subtitle: message.Card.Subtitle,
+			Image:    message.Card.ImageURI,
+		}
+		for _, b := range message.Card.Buttons {
+			data.Card.Buttons = append(data.Card.Buttons, cardButtonData{Text: b.Text, Postback: b.Postback})
+		}
+	}
+	for _, r := range message.SimpleResponses {
+		data.SimpleResponses = append(data.SimpleResponses, simpleResponseData{
+			TextToSpeech: r.TextToSpeech,
+			SSML:         r.SSML,
+			DisplayText:  r.DisplayText,
+		})
+	}
+	if message.BasicCard != nil {
+		data.BasicCard = &basicCardData{
+			Title:    message.BasicCard.Title,
+			Subtitle: message.BasicCard.Subtitle,
+			Text:     message.BasicCard.FormattedText,
+			Image:    imageToImageData(message.BasicCard.Image),
+		}
+		for _, b := range message.BasicCard.Buttons {
+			data.BasicCard.Buttons = append(data.BasicCard.Buttons, basicCardButtonData{Title: b.Title, URL: b.URI})
+		}
+	}
+	if data.Image == nil && data.QuickReplies == nil && data.Card == nil && data.Payload == nil &&
+		data.SimpleResponses == nil && data.BasicCard == nil && data.Suggestions == nil {
+		data.Text = message.Text
+		if data.Text == nil {
+			data.Text = []string{}
+		}
+	}
+	return data
+}
+
+func imageToImageData(image *Image) *imageData {
+	if image == nil {
+		return nil
+	}
+	return &imageData{URL: image.ImageURI, AccessibilityText: image.AccessibilityText}
+}
+
+// isDefaultTextMessage reports whether the message is a text response for the
+// default platform, as written to the responses of an intent.
+func isDefaultTextMessage(message Message) bool {
+	return toDialogflowPlatform(message.Platform) == dialogflowpb.Intent_Message_PLATFORM_UNSPECIFIED &&
+		message.Image == nil && message.QuickReplies == nil && message.Card == nil && message.Payload == nil &&
+		message.SimpleResponses == nil && message.BasicCard == nil && message.Suggestions == nil &&
+		message.unsupported == nil
+}
+
+// messageKind returns the name of the kind of an unsupported message.
+func messageKind(message *dialogflowpb.Intent_Message) string {
+	switch message.Message.(type) {
+	case *dialogflowpb.Intent_Message_LinkOutSuggestion_:
+		return "link out suggestion"
+	case *dialogflowpb.Intent_Message_ListSelect_:
+		return "list select"
+	case *dialogflowpb.Intent_Message_CarouselSelect_:
+		return "carousel select"
+	default:
+		return "unknown"
+	}
+}
+
+// parsePlatform returns the Dialogflow platform of a platform name such as
+// "telegram" or "google", the short name of ACTIONS_ON_GOOGLE.
+func parsePlatform(platform string) (string, error) {
+	if platform == "" {
+		return "", nil
+	}
+	if strings.EqualFold(platform, "google") {
+		return dialogflowpb.Intent_Message_ACTIONS_ON_GOOGLE.String(), nil
+	}
+	name := strings.ToUpper(strings.Replace(platform, "-", "_", -1))
+	if _, ok := dialogflowpb.Intent_Message_Platform_value[name]; !ok {
+		return "", fmt.Errorf("unknown platform %q", platform)
+	}
+	return name, nil
+}
+
+// formatPlatform returns the platform name of a Dialogflow platform, the
+// reverse of parsePlatform.
+func formatPlatform(platform string) string {
+	switch toDialogflowPlatform(platform) {
+	case dialogflowpb.Intent_Message_PLATFORM_UNSPECIFIED:
+		return ""
+	case dialogflowpb.Intent_Message_ACTIONS_ON_GOOGLE:
+		return "google"
+	default:
+		return strings.ToLower(strings.Replace(platform, "_", "-", -1))
+	}
+}
+
+// formatMessages returns the messages as plan values. The texts of the text
+// messages for the default platform are returned as they are, other messages
+// are formatted as JSON, or by their kind if unsupported, prefixed by their
+// platform.
+func formatMessages(messages []Message) []string {
+	var values []string
+	for _, m := range messages {
+		if isDefaultTextMessage(m) {
+			values = append(values, m.Text...)
+			continue
+		}
+		data := messageToMessageData(m)
+		platform := data.Platform
+		data.Platform = ""
+		b, err := json.Marshal(data)
+		if err != nil {
+			b = []byte(fmt.Sprintf("%v", data))
+		}
+		if m.unsupported != nil {
+			b = []byte(messageKind(m.unsupported) + " message")
+		}
+		if platform != "" {
+			values = append(values, fmt.Sprintf("%s: %s", platform, b))
+		} else {
+			values = append(values, string(b))
+		}
+	}
+	return values
+}
@@ -0,0 +1,140 @@
+package dialogflow
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// parameterData is a parameter of an intent in the YAML format. It overrides
+// the parameter with the same name that is derived from the training phrases,
+// or adds a parameter that is not annotated in any training phrase. The
+// entity is written as in the training phrases, such as @sys.date, but may
+// leave out the @.
+type parameterData struct {
+	Name      string   `json:"name"`
+	Entity    string   `json:"entity,omitempty"`
+	Value     string   `json:"value,omitempty"`
+	Mandatory bool     `json:"mandatory,omitempty"`
+	Prompts   []string `json:"prompts,omitempty"`
+	Default   string   `json:"default,omitempty"`
+	List      bool     `json:"list,omitempty"`
+}
+
+// trainingPhraseParameters returns a parameter for every alias annotated in
+// the training phrases, in the order they first appear.
+func trainingPhraseParameters(trainingPhrases []TrainingPhrase) []Parameter {
+	var (
+		parameters []Parameter
+		params     = make(map[string]bool)
+	)
+	for _, t := range trainingPhrases {
+		for _, p := range t.Parts {
+			if p.Alias == "" {
+				continue
+			}
+			if _, ok := params[p.Alias]; ok {
+				continue
+			}
+			params[p.Alias] = true
+			parameters = append(parameters, Parameter{
+				DisplayName:           p.Alias,
+				Value:                 fmt.Sprintf("$%s", p.Alias),
+				EntityTypeDisplayName: p.EntityType,
+			})
+		}
+	}
+	return parameters
+}
+
+// mergeParameters merges the parameters of the YAML into the parameters
+// derived from the training phrases.
+func mergeParameters(parameters []Parameter, data []parameterData) ([]Parameter, error) {
+	index := make(map[string]int)
+	for i, p := range parameters {
+		index[p.DisplayName] = i
+	}
+
+	seen := make(map[string]bool)
+	for _, d := range data {
+		if d.Name == "" {
+			return nil, errors.New("parameter name is empty")
+		}
+		if seen[d.Name] {
+			return nil, fmt.Errorf("parameter %q is defined more than once", d.Name)
+		}
+		seen[d.Name] = true
+
+		i, ok := index[d.Name]
+		if !ok {
+			if d.Entity == "" {
+				return nil, fmt.Errorf("parameter %q has no entity", d.Name)
+			}
+			parameters = append(parameters, Parameter{
+				DisplayName: d.Name,
+				Value:       fmt.Sprintf("$%s", d.Name),
+			})
+			i = len(parameters) - 1
+		}
+
+		p := &parameters[i]
+		if d.Entity != "" {
+			p.EntityTypeDisplayName = "@" + strings.TrimPrefix(d.Entity, "@")
+		}
+		if d.Value != "" {
+			p.Value = d.Value
+		}
+		p.Mandatory = d.Mandatory
+		p.Prompts = d.Prompts
+		p.DefaultValue = d.Default
+		p.IsList = d.List
+
+		if p.Mandatory && len(p.Prompts) == 0 {
+			return nil, fmt.Errorf("mandatory parameter %q has no prompts", d.Name)
+		}
+	}
+
+	return parameters, nil
+}
+
+// parametersToParameterData returns the parameters that differ from the
+// parameters derived from the training phrases, as written to the YAML.
+func parametersToParameterData(parameters []Parameter, trainingPhrases []TrainingPhrase) []parameterData {
+	derived := make(map[string]Parameter)
+	for _, p := range trainingPhraseParameters(trainingPhrases) {
+		derived[p.DisplayName] = p
+	}
+
+	var data []parameterData
+	for _, p := range parameters {
+		d, ok := derived[p.DisplayName]
+		if ok && reflect.DeepEqual(d, Parameter{
+			DisplayName:           p.DisplayName,
+			Value:                 p.Value,
+			EntityTypeDisplayName: p.EntityTypeDisplayName,
+			Mandatory:             p.Mandatory,
+			Prompts:               p.Prompts,
+			DefaultValue:          p.DefaultValue,
+			IsList:                p.IsList,
+		}) {
+			continue
+		}
+
+		parameter := parameterData{
+			Name:      p.DisplayName,
+			Mandatory: p.Mandatory,
+			Prompts:   p.Prompts,
+			Default:   p.DefaultValue,
+			List:      p.IsList,
+		}
+		if !ok || p.EntityTypeDisplayName != d.EntityTypeDisplayName {
+			parameter.Entity = p.EntityTypeDisplayName
+		}
+		if p.Value != fmt.Sprintf("$%s", p.DisplayName) {
+			parameter.Value = p.Value
+		}
+		data = append(data, parameter)
+	}
+	return data
+}
@@ -1,31 +1,58 @@
 package dialogflow
 
+import (
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+)
+
+const (
+	IntentViewUnspecified = "INTENT_VIEW_UNSPECIFIED"
+	IntentViewFull        = "INTENT_VIEW_FULL"
+)
+
 type Intent struct {
 	Name                     string
 	DisplayName              string
+	WebhookState             string
 	Priority                 int32
 	IsFallback               bool
+	MlDisabled               bool
 	TrainingPhrases          []TrainingPhrase
 	Action                   string
 	InputContextNames        []string
+	Events                   []string
 	OutputContexts           []Context
+	ResetContexts            bool
 	Parameters               []Parameter
 	Messages                 []Message
+	DefaultResponsePlatforms []string
 	RootFollowupIntentName   string
 	ParentFollowupIntentName string
 	FollowupIntents          []Intent
 	FollowupIntentInfo       []FollowupIntentInfo
+
+	// LanguageCode is the language of the training phrases, messages and
+	// parameter prompts, or empty for the default language of the agent.
+	LanguageCode string
+	// Translations hold the training phrases, messages and parameter
+	// prompts of the intent in other languages.
+	Translations []Intent
 }
 
+// DefaultIntentPriority is the priority Dialogflow gives to intents without a
+// priority.
+const DefaultIntentPriority = 500000
+
 type Context struct {
 	Name          string
 	LifespanCount int32
-	Parameters    []string
+	Parameters    map[string]interface{}
 }
 
 type TrainingPhrase struct {
-	Name  string
-	Parts []TrainingPhrasePart
+	Name            string
+	Type            string
+	Parts           []TrainingPhrasePart
+	TimesAddedCount int32
 }
 
 type TrainingPhrasePart struct {
@@ -46,9 +73,64 @@ type Parameter struct {
 	IsList                bool
 }
 
+// Message is a response of an intent for a platform. Only one kind of
+// response is set: Image, QuickReplies, Card, Payload, SimpleResponses,
+// BasicCard or Suggestions, and otherwise Text.
 type Message struct {
+	Text            []string
+	Image           *Image
+	QuickReplies    *QuickReplies
+	Card            *Card
+	Payload         map[string]interface{}
+	SimpleResponses []SimpleResponse
+	BasicCard       *BasicCard
+	Suggestions     []string
+	Platform        string
+
+	// unsupported is a message of a kind that can not be written as YAML,
+	// such as a list select, kept to send it back to Dialogflow unchanged.
+	unsupported *dialogflowpb.Intent_Message
+}
+
+type Image struct {
+	ImageURI          string
+	AccessibilityText string
+}
+
+type QuickReplies struct {
+	Title        string
+	QuickReplies []string
+}
+
+type Card struct {
+	Title    string
+	Subtitle string
+	ImageURI string
+	Buttons  []CardButton
+}
+
+type CardButton struct {
 	Text     string
-	Platform string
+	Postback string
+}
+
+type SimpleResponse struct {
+	TextToSpeech string
+	SSML         string
+	DisplayText  string
+}
+
+type BasicCard struct {
+	Title         string
+	Subtitle      string
+	FormattedText string
+	Image         *Image
+	Buttons       []BasicCardButton
+}
+
+type BasicCardButton struct {
+	Title string
+	URI   string
 }
 
 type FollowupIntentInfo struct {
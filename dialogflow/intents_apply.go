@@ -0,0 +1,137 @@
+package dialogflow
+
+import (
+	"context"
+	"fmt"
+)
+
+type IntentsApplier interface {
+	PlanIntents(ctx context.Context) ([]IntentChange, error)
+	ApplyIntents(ctx context.Context) error
+	PruneIntents(ctx context.Context) error
+}
+
+type intentsApplier struct {
+	intentsClient IntentsAPI
+	source        Source
+}
+
+func NewIntentsApplier(intentsClient IntentsAPI, source Source) IntentsApplier {
+	return &intentsApplier{
+		intentsClient: intentsClient,
+		source:        source,
+	}
+}
+
+func (applier *intentsApplier) PlanIntents(ctx context.Context) ([]IntentChange, error) {
+	changes, _, err := applier.plan(ctx)
+	return changes, err
+}
+
+func (applier *intentsApplier) ApplyIntents(ctx context.Context) error {
+	changes, remoteIntents, err := applier.plan(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]string)
+	for _, intent := range remoteIntents {
+		names[intent.DisplayName] = intent.Name
+	}
+
+	for _, change := range changes {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		intent := change.intent
+		intent.ParentFollowupIntentName = names[change.parentDisplayName]
+
+		switch {
+		case change.LanguageCode != "":
+			// Translations follow the intents, so added intents have a name.
+			intent.Name = names[change.DisplayName]
+			if _, err := applier.intentsClient.UpdateIntent(ctx, intent, "training_phrases", "messages", "parameters"); err != nil {
+				return fmt.Errorf("update intent %q in language %q: %v", change.DisplayName, change.LanguageCode, err)
+			}
+		case change.Action == ChangeActionAdd:
+			newIntent, err := applier.intentsClient.CreateIntent(ctx, intent)
+			if err != nil {
+				return fmt.Errorf("create intent: %v", err)
+			}
+			names[change.DisplayName] = newIntent.Name
+		case change.Action == ChangeActionChange:
+			intent.Name = change.remoteIntent.Name
+			if _, err := applier.intentsClient.UpdateIntent(ctx, intent); err != nil {
+				return fmt.Errorf("update intent: %v", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (applier *intentsApplier) PruneIntents(ctx context.Context) error {
+	changes, _, err := applier.plan(ctx)
+	if err != nil {
+		return err
+	}
+
+	pruned := make(map[string]bool)
+	for _, change := range changes {
+		if change.Action == ChangeActionRemove {
+			pruned[change.remoteIntent.Name] = true
+		}
+	}
+
+	// Deleting an intent also deletes its followup intents, so those are
+	// left out of the batch.
+	var deleteIntents []Intent
+	for _, change := range changes {
+		intent := change.remoteIntent
+		if pruned[intent.Name] && !pruned[intent.ParentFollowupIntentName] {
+			deleteIntents = append(deleteIntents, Intent{Name: intent.Name})
+		}
+	}
+
+	if len(deleteIntents) == 0 {
+		return nil
+	}
+
+	op, err := applier.intentsClient.DeleteIntents(ctx, deleteIntents)
+	if err != nil {
+		return fmt.Errorf("delete intents: %v", err)
+	}
+	if err = WaitOperation(ctx, op, DefaultPollInterval, nil); err != nil {
+		return fmt.Errorf("wait for delete intents: %v", err)
+	}
+
+	return nil
+}
+
+func (applier *intentsApplier) plan(ctx context.Context) ([]IntentChange, []Intent, error) {
+	data, err := applier.source.ReadAll(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read data: %v", err)
+	}
+
+	intents, err := readIntents(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read intents: %v", sourceError(applier.source, err))
+	}
+
+	remoteIntents, err := applier.intentsClient.ListIntents(ctx, IntentViewFull, "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("list intents: %v", err)
+	}
+
+	remoteTranslations := make(map[string][]Intent)
+	for _, languageCode := range intentLanguageCodes(intents) {
+		remoteTranslations[languageCode], err = applier.intentsClient.ListIntents(ctx, IntentViewFull, languageCode)
+		if err != nil {
+			return nil, nil, fmt.Errorf("list intents in language %q: %v", languageCode, err)
+		}
+	}
+
+	return planIntents(intents, remoteIntents, remoteTranslations), remoteIntents, nil
+}
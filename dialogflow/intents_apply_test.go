@@ -0,0 +1,580 @@
+package dialogflow_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow/dialogflowtest"
+)
+
+func newTestIntentsClient(t *testing.T, server *dialogflowtest.Server) *dialogflow.IntentsClient {
+	intentsClient, err := dialogflow.NewIntentsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return intentsClient
+}
+
+// newTestSource writes the data to a temporary file and returns a source for
+// it, together with a function that removes the file.
+func newTestSource(t *testing.T, data string) (dialogflow.Source, func()) {
+	file, err := ioutil.TempFile("", "dialogflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remove := func() {
+		_ = os.Remove(file.Name())
+	}
+	if _, err = file.WriteString(data); err != nil {
+		remove()
+		t.Fatal(err)
+	}
+	if err = file.Close(); err != nil {
+		remove()
+		t.Fatal(err)
+	}
+	return dialogflow.NewFileSource(file.Name()), remove
+}
+
+func serverIntents(server *dialogflowtest.Server) map[string]string {
+	names := make(map[string]string)
+	for _, intent := range server.Intents() {
+		names[intent.Name] = intent.DisplayName
+	}
+
+	intents := make(map[string]string)
+	for _, intent := range server.Intents() {
+		intents[intent.DisplayName] = names[intent.ParentFollowupIntentName]
+	}
+	return intents
+}
+
+func TestImportIntents(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err := importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"My name is @name": "",
+		"I am good":        "My name is @name",
+		"Not good":         "My name is @name",
+		"How are you?":     "",
+	}
+
+	if intents := serverIntents(server); !reflect.DeepEqual(expected, intents) {
+		t.Errorf("expected %v, got %v", expected, intents)
+	}
+
+	intents, err := intentsClient.ListIntents(context.Background(), dialogflow.IntentViewFull, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, intent := range intents {
+		if intent.DisplayName == "My name is @name" && len(intent.FollowupIntentInfo) != 2 {
+			t.Errorf("expected 2 followup intents, got %v", intent.FollowupIntentInfo)
+		}
+		if len(intent.TrainingPhrases) == 0 {
+			t.Errorf("expected training phrases for intent %q", intent.DisplayName)
+		}
+	}
+}
+
+func TestImportIntentsRecorder(t *testing.T) {
+	intentsRecorder := dialogflowtest.NewIntentsRecorder()
+
+	importer := dialogflow.NewIntentsImporter(intentsRecorder, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err := importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"CreateIntent", "CreateFollowupIntent", "CreateFollowupIntent", "CreateIntent"}
+	if methods := intentsRecorder.Methods(); !reflect.DeepEqual(expected, methods) {
+		t.Errorf("expected %v, got %v", expected, methods)
+	}
+
+	parent := intentsRecorder.Calls[1].Args[1].(dialogflow.Intent)
+	if parent.Name != intentsRecorder.Intents[0].Name {
+		t.Errorf("expected parent %q, got %q", intentsRecorder.Intents[0].Name, parent.Name)
+	}
+
+	intentsRecorder = dialogflowtest.NewIntentsRecorder()
+	intentsRecorder.Errors["CreateFollowupIntent"] = errors.New("quota exceeded")
+
+	importer = dialogflow.NewIntentsImporter(intentsRecorder, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err := importer.ImportIntents(context.Background()); err == nil {
+		t.Error("expected error")
+	}
+
+	expected = []string{"CreateIntent", "CreateFollowupIntent"}
+	if methods := intentsRecorder.Methods(); !reflect.DeepEqual(expected, methods) {
+		t.Errorf("expected %v, got %v", expected, methods)
+	}
+}
+
+// cancelIntentsRecorder cancels the context once the first intent is created.
+type cancelIntentsRecorder struct {
+	*dialogflowtest.IntentsRecorder
+	cancel context.CancelFunc
+}
+
+func (r cancelIntentsRecorder) CreateIntent(ctx context.Context, intent dialogflow.Intent) (dialogflow.Intent, error) {
+	defer r.cancel()
+	return r.IntentsRecorder.CreateIntent(ctx, intent)
+}
+
+func TestImportIntentsCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	intentsRecorder := dialogflowtest.NewIntentsRecorder()
+
+	importer := dialogflow.NewIntentsImporter(
+		cancelIntentsRecorder{IntentsRecorder: intentsRecorder, cancel: cancel},
+		dialogflow.NewFileSource("../examples/intents.yaml"),
+	)
+	if err := importer.ImportIntents(ctx); err != context.Canceled {
+		t.Errorf("expected %v, got %v", context.Canceled, err)
+	}
+
+	expected := []string{"CreateIntent"}
+	if methods := intentsRecorder.Methods(); !reflect.DeepEqual(expected, methods) {
+		t.Errorf("expected %v, got %v", expected, methods)
+	}
+}
+
+func TestApplyIntents(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	applier := dialogflow.NewIntentsApplier(intentsClient, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err := applier.ApplyIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := applier.PlanIntents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes after apply, got %+v", changes)
+	}
+
+	source, remove := newTestSource(t, `
+intents:
+  - name: My name is @name
+    usersays:
+      - Hi, my name is @name:John
+    responses:
+      - Hello $name, how are you doing?
+    followup:
+      - name: I am good
+        usersays:
+          - I am good
+        responses:
+          - Great
+  - name: Goodbye
+    usersays:
+      - Bye
+`)
+	defer remove()
+
+	applier = dialogflow.NewIntentsApplier(intentsClient, source)
+	if err = applier.ApplyIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err = applier.PruneIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"My name is @name": "",
+		"I am good":        "My name is @name",
+		"Goodbye":          "",
+	}
+
+	if intents := serverIntents(server); !reflect.DeepEqual(expected, intents) {
+		t.Errorf("expected %v, got %v", expected, intents)
+	}
+
+	intents, err := intentsClient.ListIntents(context.Background(), dialogflow.IntentViewFull, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var responses []string
+	for _, intent := range intents {
+		for _, message := range intent.Messages {
+			responses = append(responses, message.Text...)
+		}
+	}
+	sort.Strings(responses)
+	if expected := []string{"Great", "Hello $name, how are you doing?"}; !reflect.DeepEqual(expected, responses) {
+		t.Errorf("expected %v, got %v", expected, responses)
+	}
+
+	changes, err = applier.PlanIntents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes after apply, got %+v", changes)
+	}
+}
+
+func TestImportIntentsContexts(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	source, remove := newTestSource(t, `
+intents:
+  - name: Order pizza
+    usersays:
+      - I want a pizza
+    contexts:
+      out:
+        - order
+        - name: pizza
+          lifespan: 2
+  - name: Order size
+    usersays:
+      - Large
+    contexts:
+      in:
+        - order
+      out:
+        - name: order
+          lifespan: 0
+`)
+	defer remove()
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, source)
+	if err := importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	contexts := make(map[string][]string)
+	for _, intent := range server.Intents() {
+		contexts[intent.DisplayName] = append(contexts[intent.DisplayName], intent.InputContextNames...)
+		for _, outputContext := range intent.OutputContexts {
+			contexts[intent.DisplayName] = append(contexts[intent.DisplayName], fmt.Sprintf("%s %d", outputContext.Name, outputContext.LifespanCount))
+		}
+	}
+
+	expected := map[string][]string{
+		"Order pizza": {
+			"projects/example/agent/sessions/-/contexts/order 5",
+			"projects/example/agent/sessions/-/contexts/pizza 2",
+		},
+		"Order size": {
+			"projects/example/agent/sessions/-/contexts/order",
+			"projects/example/agent/sessions/-/contexts/order 0",
+		},
+	}
+
+	if !reflect.DeepEqual(expected, contexts) {
+		t.Errorf("expected %v, got %v", expected, contexts)
+	}
+}
+
+func TestImportIntentsLanguages(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	source, remove := newTestSource(t, `
+intents:
+  - name: Order pizza
+    usersays:
+      - A @size:large pizza
+    responses:
+      - Your pizza is on its way
+    parameters:
+      - name: size
+        mandatory: true
+        prompts:
+          - What size?
+    languages:
+      de:
+        usersays:
+          - Eine @size:große Pizza
+        responses:
+          - Deine Pizza ist unterwegs
+        prompts:
+          size:
+            - Welche Größe?
+      nl:
+        usersays:
+          - Een @size:grote pizza
+        prompts:
+          size:
+            - Welke maat?
+`)
+	defer remove()
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, source, dialogflow.WithLanguages("de"))
+	if err := importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		languageCode string
+		phrases      []string
+		prompts      []string
+	}{
+		{languageCode: "en", phrases: []string{"A large pizza"}, prompts: []string{"What size?"}},
+		{languageCode: "de", phrases: []string{"Eine große Pizza"}, prompts: []string{"Welche Größe?"}},
+		{languageCode: "nl"},
+	}
+
+	for _, test := range tests {
+		intents := server.LocalizedIntents(test.languageCode)
+		if len(intents) != 1 {
+			t.Fatalf("%s: expected 1 intent, got %d", test.languageCode, len(intents))
+		}
+
+		var phrases []string
+		for _, trainingPhrase := range intents[0].TrainingPhrases {
+			var phrase string
+			for _, part := range trainingPhrase.Parts {
+				phrase += part.Text
+			}
+			phrases = append(phrases, phrase)
+		}
+		if !reflect.DeepEqual(test.phrases, phrases) {
+			t.Errorf("%s: expected training phrases %v, got %v", test.languageCode, test.phrases, phrases)
+		}
+		if prompts := intents[0].Parameters[0].Prompts; !reflect.DeepEqual(test.prompts, prompts) {
+			t.Errorf("%s: expected prompts %v, got %v", test.languageCode, test.prompts, prompts)
+		}
+	}
+}
+
+func TestImportIntentsLimits(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	source, remove := newTestSource(t, `
+intents:
+  - name: Hello
+    usersays:
+      - Hi
+  - name: Order pizza
+    usersays:
+      - I want a pizza
+      - A pizza please
+`)
+	defer remove()
+
+	limits := dialogflow.DefaultLimits
+	limits.MaxTrainingPhrases = 1
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, source, dialogflow.WithLimits(limits))
+	err := importer.ImportIntents(context.Background())
+	if _, ok := err.(dialogflow.LimitsError); !ok {
+		t.Fatalf("expected limits error, got %v", err)
+	}
+
+	if intents := server.Intents(); len(intents) != 0 {
+		t.Errorf("expected no intents, got %d", len(intents))
+	}
+}
+
+func TestApplyIntentsLanguages(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	source, remove := newTestSource(t, `
+intents:
+  - name: Hello
+    usersays:
+      - Hi
+    followup:
+      - name: Hello - yes
+        usersays:
+          - Yes
+        languages:
+          de:
+            usersays:
+              - Ja
+`)
+	defer remove()
+
+	applier := dialogflow.NewIntentsApplier(intentsClient, source)
+	if err := applier.ApplyIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	source, remove = newTestSource(t, `
+intents:
+  - name: Hello
+    usersays:
+      - Hi
+    languages:
+      de:
+        usersays:
+          - Hallo
+    followup:
+      - name: Hello - yes
+        usersays:
+          - Yes
+        languages:
+          de:
+            usersays:
+              - Ja
+              - Jawohl
+`)
+	defer remove()
+
+	applier = dialogflow.NewIntentsApplier(intentsClient, source)
+	changes, err := applier.PlanIntents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []dialogflow.IntentChange{
+		{
+			Action:          dialogflow.ChangeActionChange,
+			DisplayName:     "Hello",
+			LanguageCode:    "de",
+			TrainingPhrases: []dialogflow.ValueChange{{Action: dialogflow.ChangeActionAdd, Value: "Hallo"}},
+		},
+		{
+			Action:          dialogflow.ChangeActionChange,
+			DisplayName:     "Hello - yes",
+			LanguageCode:    "de",
+			TrainingPhrases: []dialogflow.ValueChange{{Action: dialogflow.ChangeActionAdd, Value: "Jawohl"}},
+		},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %+v", len(expected), changes)
+	}
+	for i, change := range changes {
+		if change.Action != expected[i].Action || change.DisplayName != expected[i].DisplayName ||
+			change.LanguageCode != expected[i].LanguageCode || !reflect.DeepEqual(change.TrainingPhrases, expected[i].TrainingPhrases) {
+			t.Errorf("expected %+v, got %+v", expected[i], change)
+		}
+	}
+
+	if err = applier.ApplyIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	phrases := make(map[string][]string)
+	for _, intent := range server.LocalizedIntents("de") {
+		for _, trainingPhrase := range intent.TrainingPhrases {
+			var phrase string
+			for _, part := range trainingPhrase.Parts {
+				phrase += part.Text
+			}
+			phrases[intent.DisplayName] = append(phrases[intent.DisplayName], phrase)
+		}
+	}
+	expectedPhrases := map[string][]string{
+		"Hello":       {"Hallo"},
+		"Hello - yes": {"Ja", "Jawohl"},
+	}
+	if !reflect.DeepEqual(expectedPhrases, phrases) {
+		t.Errorf("expected %v, got %v", expectedPhrases, phrases)
+	}
+
+	if changes, err = applier.PlanIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes after apply, got %+v", changes)
+	}
+}
+
+func TestExportIntentsLanguages(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	source, remove := newTestSource(t, `
+intents:
+  - name: Order pizza
+    usersays:
+      - A @size:large pizza
+    responses:
+      - Your pizza is on its way
+    parameters:
+      - name: size
+        mandatory: true
+        prompts:
+          - What size?
+    languages:
+      de:
+        usersays:
+          - Eine @size:große Pizza
+        responses:
+          - Deine Pizza ist unterwegs
+        prompts:
+          size:
+            - Welche Größe?
+    followup:
+      - name: Order pizza - cancel
+        usersays:
+          - Cancel
+        languages:
+          nl:
+            usersays:
+              - Annuleren
+`)
+	defer remove()
+
+	if err := dialogflow.NewIntentsImporter(intentsClient, source).ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := dialogflow.NewIntentsExporter(intentsClient, &buf, "de", "nl").ExportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The exported intents and translations are the imported ones.
+	exported, remove := newTestSource(t, buf.String())
+	defer remove()
+
+	changes, err := dialogflow.NewIntentsApplier(intentsClient, exported).PlanIntents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v in\n%s", changes, buf.String())
+	}
+	for _, s := range []string{"Eine @size:'große' Pizza", "Welche Größe?", "Annuleren"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected %q in\n%s", s, buf.String())
+		}
+	}
+}
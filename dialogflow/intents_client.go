@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path"
+	"strings"
 
 	"cloud.google.com/go/dialogflow/apiv2"
 	"google.golang.org/api/iterator"
@@ -11,15 +13,27 @@ import (
 	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
 )
 
+// IntentsAPI is the interface of the intents client, so that code using it
+// can be tested with a fake.
+type IntentsAPI interface {
+	ListIntents(ctx context.Context, intentView, languageCode string) ([]Intent, error)
+	GetIntent(ctx context.Context, intentID, intentView string) (Intent, error)
+	CreateIntent(ctx context.Context, intent Intent) (Intent, error)
+	UpdateIntent(ctx context.Context, intent Intent, updateMask ...string) (Intent, error)
+	CreateFollowupIntent(ctx context.Context, followupIntent Intent, parentFollowupIntent Intent) (Intent, error)
+	DeleteIntent(ctx context.Context, intentID string) error
+	DeleteIntents(ctx context.Context, intents []Intent) (Operation, error)
+}
+
+var _ IntentsAPI = (*IntentsClient)(nil)
+
 type IntentsClient struct {
 	projectID     string
 	intentsClient *dialogflow.IntentsClient
 }
 
-func NewIntentsClient(projectID, credentialsFile string) (*IntentsClient, error) {
-	ctx := context.Background()
-
-	intentsClient, err := dialogflow.NewIntentsClient(ctx, option.WithCredentialsFile(credentialsFile))
+func NewIntentsClient(ctx context.Context, projectID string, opts ...option.ClientOption) (*IntentsClient, error) {
+	intentsClient, err := dialogflow.NewIntentsClient(ctx, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -30,11 +44,16 @@ func NewIntentsClient(projectID, credentialsFile string) (*IntentsClient, error)
 	}, nil
 }
 
-func (client *IntentsClient) ListIntents() ([]Intent, error) {
+// ListIntents lists the intents with the training phrases, messages and
+// parameter prompts in the language, or in the default language of the agent
+// if the language code is empty.
+func (client *IntentsClient) ListIntents(ctx context.Context, intentView, languageCode string) ([]Intent, error) {
 	iter := client.intentsClient.ListIntents(
-		context.Background(),
+		ctx,
 		&dialogflowpb.ListIntentsRequest{
-			Parent: fmt.Sprintf("projects/%s/agent", client.projectID),
+			Parent:       fmt.Sprintf("projects/%s/agent", client.projectID),
+			IntentView:   toDialogflowIntentView(intentView),
+			LanguageCode: languageCode,
 		},
 	)
 
@@ -47,52 +66,105 @@ func (client *IntentsClient) ListIntents() ([]Intent, error) {
 		if err != nil {
 			return nil, err
 		}
-		intents = append(intents, dialogflowIntentToIntent(intent))
+		i := dialogflowIntentToIntent(intent)
+		i.LanguageCode = languageCode
+		intents = append(intents, i)
 	}
 
 	return intents, nil
 }
 
-func (client *IntentsClient) CreateIntent(intent Intent) (Intent, error) {
+func (client *IntentsClient) GetIntent(ctx context.Context, intentID, intentView string) (Intent, error) {
+	intent, err := client.intentsClient.GetIntent(
+		ctx,
+		&dialogflowpb.GetIntentRequest{
+			Name:       fmt.Sprintf("projects/%s/agent/intents/%s", client.projectID, intentID),
+			IntentView: toDialogflowIntentView(intentView),
+		},
+	)
+	if err != nil {
+		return Intent{}, err
+	}
+
+	return dialogflowIntentToIntent(intent), nil
+}
+
+func (client *IntentsClient) CreateIntent(ctx context.Context, intent Intent) (Intent, error) {
 	if intent.DisplayName == "" {
 		return Intent{}, errors.New("display name is empty")
 	}
 
+	dialogflowIntent := toDialogflowIntent(intent)
+	client.expandContextNames(dialogflowIntent)
+	dialogflowIntent.Name = ""
+	dialogflowIntent.RootFollowupIntentName = ""
+	dialogflowIntent.FollowupIntentInfo = nil
+
 	dialogflowIntent, err := client.intentsClient.CreateIntent(
-		context.Background(),
+		ctx,
 		&dialogflowpb.CreateIntentRequest{
-			Parent: fmt.Sprintf("projects/%s/agent", client.projectID),
-			Intent: &dialogflowpb.Intent{
-				DisplayName:              intent.DisplayName,
-				WebhookState:             dialogflowpb.Intent_WEBHOOK_STATE_UNSPECIFIED,
-				TrainingPhrases:          toDialogflowTrainingPhrases(intent.TrainingPhrases),
-				Messages:                 toDialogflowIntentMessages(intent.Messages),
-				Parameters:               toDialogflowParameters(intent.Parameters),
-				ParentFollowupIntentName: intent.ParentFollowupIntentName,
-			},
+			Parent:       fmt.Sprintf("projects/%s/agent", client.projectID),
+			Intent:       dialogflowIntent,
+			LanguageCode: intent.LanguageCode,
+			IntentView:   dialogflowpb.IntentView_INTENT_VIEW_FULL,
+		},
+	)
+	if err != nil {
+		return Intent{}, err
+	}
+
+	newIntent := dialogflowIntentToIntent(dialogflowIntent)
+	newIntent.LanguageCode = intent.LanguageCode
+
+	return newIntent, nil
+}
+
+func (client *IntentsClient) UpdateIntent(ctx context.Context, intent Intent, updateMask ...string) (Intent, error) {
+	if intent.Name == "" {
+		return Intent{}, errors.New("intent name is empty")
+	}
+	if intent.DisplayName == "" {
+		return Intent{}, errors.New("display name is empty")
+	}
+
+	dialogflowIntent := toDialogflowIntent(intent)
+	client.expandContextNames(dialogflowIntent)
+	dialogflowIntent.RootFollowupIntentName = ""
+	dialogflowIntent.FollowupIntentInfo = nil
+
+	dialogflowIntent, err := client.intentsClient.UpdateIntent(
+		ctx,
+		&dialogflowpb.UpdateIntentRequest{
+			Intent:       dialogflowIntent,
+			LanguageCode: intent.LanguageCode,
+			UpdateMask:   toFieldMask(updateMask),
+			IntentView:   dialogflowpb.IntentView_INTENT_VIEW_FULL,
 		},
 	)
 	if err != nil {
 		return Intent{}, err
 	}
 
-	return dialogflowIntentToIntent(dialogflowIntent), nil
+	updatedIntent := dialogflowIntentToIntent(dialogflowIntent)
+	updatedIntent.LanguageCode = intent.LanguageCode
+
+	return updatedIntent, nil
 }
 
-func (client *IntentsClient) CreateFollowupIntent(intent Intent, parentFollowupIntent Intent) (Intent, error) {
+func (client *IntentsClient) CreateFollowupIntent(ctx context.Context, intent Intent, parentFollowupIntent Intent) (Intent, error) {
 	if parentFollowupIntent.Name == "" {
 		return Intent{}, errors.New("parent followup intent name is empty")
 	}
 	intent.ParentFollowupIntentName = parentFollowupIntent.Name
 
-	return client.CreateIntent(intent)
+	return client.CreateIntent(ctx, intent)
 }
 
-func (client *IntentsClient) DeleteIntent(intentID string) error {
+func (client *IntentsClient) DeleteIntent(ctx context.Context, intentID string) error {
 	if intentID == "" {
 		return errors.New("missing intent id")
 	}
-	err := client.intentsClient.DeleteIntent(context.Background(), &dialogflowpb.DeleteIntentRequest{
+	err := client.intentsClient.DeleteIntent(ctx, &dialogflowpb.DeleteIntentRequest{
 		Name: fmt.Sprintf("projects/%s/agent/intents/%s", client.projectID, intentID),
 	})
 	if err != nil {
@@ -101,46 +173,145 @@ func (client *IntentsClient) DeleteIntent(intentID string) error {
 	return nil
 }
 
-func (client *IntentsClient) DeleteIntents(intents []Intent) error {
+// DeleteIntents starts deleting the intents and returns the operation, which
+// the caller can wait on with WaitOperation.
+func (client *IntentsClient) DeleteIntents(ctx context.Context, intents []Intent) (Operation, error) {
 	var dialogflowIntents []*dialogflowpb.Intent
 	for _, intent := range intents {
 		dialogflowIntents = append(dialogflowIntents, &dialogflowpb.Intent{Name: intent.Name})
 	}
-	_, err := client.intentsClient.BatchDeleteIntents(
-		context.Background(),
+	op, err := client.intentsClient.BatchDeleteIntents(
+		ctx,
 		&dialogflowpb.BatchDeleteIntentsRequest{
 			Parent:  fmt.Sprintf("projects/%s/agent", client.projectID),
 			Intents: dialogflowIntents,
 		},
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return batchDeleteIntentsOperation{op}, nil
 }
 
 func (client *IntentsClient) Close() error {
 	return client.intentsClient.Close()
 }
 
+// expandContextNames expands the short context names of the intent, such as
+// "myname-followup", to context resource names.
+func (client *IntentsClient) expandContextNames(intent *dialogflowpb.Intent) {
+	inputContextNames := make([]string, len(intent.InputContextNames))
+	for i, name := range intent.InputContextNames {
+		inputContextNames[i] = contextName(client.projectID, name)
+	}
+	if len(inputContextNames) > 0 {
+		intent.InputContextNames = inputContextNames
+	}
+	for _, outputContext := range intent.OutputContexts {
+		outputContext.Name = contextName(client.projectID, outputContext.Name)
+	}
+}
+
+// contextName returns the resource name of the context with the given short
+// name. Names that already are resource names are returned as they are.
+func contextName(projectID, name string) string {
+	if strings.Contains(name, "/") {
+		return name
+	}
+	return fmt.Sprintf("projects/%s/agent/sessions/-/contexts/%s", projectID, name)
+}
+
+// shortContextName returns the short name of a context resource name.
+func shortContextName(name string) string {
+	return path.Base(name)
+}
+
+func toDialogflowIntentView(intentView string) dialogflowpb.IntentView {
+	if val, ok := dialogflowpb.IntentView_value[intentView]; ok {
+		return dialogflowpb.IntentView(val)
+	}
+	return dialogflowpb.IntentView_INTENT_VIEW_UNSPECIFIED
+}
+
 func dialogflowIntentToIntent(dialogflowIntent *dialogflowpb.Intent) Intent {
+	var defaultResponsePlatforms []string
+	for _, platform := range dialogflowIntent.DefaultResponsePlatforms {
+		defaultResponsePlatforms = append(defaultResponsePlatforms, platform.String())
+	}
+
+	var followupIntentInfo []FollowupIntentInfo
+	for _, info := range dialogflowIntent.FollowupIntentInfo {
+		followupIntentInfo = append(followupIntentInfo, FollowupIntentInfo{
+			FollowupIntentName:       info.FollowupIntentName,
+			ParentFollowupIntentName: info.ParentFollowupIntentName,
+		})
+	}
+
 	return Intent{
 		Name:                     dialogflowIntent.Name,
 		DisplayName:              dialogflowIntent.DisplayName,
+		WebhookState:             dialogflowIntent.WebhookState.String(),
 		Priority:                 dialogflowIntent.Priority,
 		IsFallback:               dialogflowIntent.IsFallback,
+		MlDisabled:               dialogflowIntent.MlDisabled,
 		TrainingPhrases:          toTrainingPhrases(dialogflowIntent.TrainingPhrases),
 		Action:                   dialogflowIntent.Action,
-		InputContextNames:        nil,
-		OutputContexts:           nil,
-		Parameters:               nil,
-		Messages:                 nil,
+		InputContextNames:        dialogflowIntent.InputContextNames,
+		Events:                   dialogflowIntent.Events,
+		OutputContexts:           toContexts(dialogflowIntent.OutputContexts),
+		ResetContexts:            dialogflowIntent.ResetContexts,
+		Parameters:               toParameters(dialogflowIntent.Parameters),
+		Messages:                 toMessages(dialogflowIntent.Messages),
+		DefaultResponsePlatforms: defaultResponsePlatforms,
 		RootFollowupIntentName:   dialogflowIntent.RootFollowupIntentName,
 		ParentFollowupIntentName: dialogflowIntent.ParentFollowupIntentName,
-		FollowupIntentInfo:       nil,
+		FollowupIntentInfo:       followupIntentInfo,
+	}
+}
+
+func toDialogflowIntent(intent Intent) *dialogflowpb.Intent {
+	var defaultResponsePlatforms []dialogflowpb.Intent_Message_Platform
+	for _, platform := range intent.DefaultResponsePlatforms {
+		defaultResponsePlatforms = append(defaultResponsePlatforms, toDialogflowPlatform(platform))
+	}
+
+	var followupIntentInfo []*dialogflowpb.Intent_FollowupIntentInfo
+	for _, info := range intent.FollowupIntentInfo {
+		followupIntentInfo = append(followupIntentInfo, &dialogflowpb.Intent_FollowupIntentInfo{
+			FollowupIntentName:       info.FollowupIntentName,
+			ParentFollowupIntentName: info.ParentFollowupIntentName,
+		})
+	}
+
+	return &dialogflowpb.Intent{
+		Name:                     intent.Name,
+		DisplayName:              intent.DisplayName,
+		WebhookState:             toDialogflowWebhookState(intent.WebhookState),
+		Priority:                 intent.Priority,
+		IsFallback:               intent.IsFallback,
+		MlDisabled:               intent.MlDisabled,
+		InputContextNames:        intent.InputContextNames,
+		Events:                   intent.Events,
+		TrainingPhrases:          toDialogflowTrainingPhrases(intent.TrainingPhrases),
+		Action:                   intent.Action,
+		OutputContexts:           toDialogflowContexts(intent.OutputContexts),
+		ResetContexts:            intent.ResetContexts,
+		Parameters:               toDialogflowParameters(intent.Parameters),
+		Messages:                 toDialogflowIntentMessages(intent.Messages),
+		DefaultResponsePlatforms: defaultResponsePlatforms,
+		RootFollowupIntentName:   intent.RootFollowupIntentName,
+		ParentFollowupIntentName: intent.ParentFollowupIntentName,
+		FollowupIntentInfo:       followupIntentInfo,
 	}
 }
 
+func toDialogflowWebhookState(webhookState string) dialogflowpb.Intent_WebhookState {
+	if val, ok := dialogflowpb.Intent_WebhookState_value[webhookState]; ok {
+		return dialogflowpb.Intent_WebhookState(val)
+	}
+	return dialogflowpb.Intent_WEBHOOK_STATE_UNSPECIFIED
+}
+
 func toTrainingPhrases(dialogflowTrainingPhrases []*dialogflowpb.Intent_TrainingPhrase) []TrainingPhrase {
 	var trainingPhrases []TrainingPhrase
 	for _, t := range dialogflowTrainingPhrases {
@@ -154,8 +325,10 @@ func toTrainingPhrases(dialogflowTrainingPhrases []*dialogflowpb.Intent_Training
 			})
 		}
 		trainingPhrases = append(trainingPhrases, TrainingPhrase{
-			Name:  t.Name,
-			Parts: parts,
+			Name:            t.Name,
+			Type:            t.Type.String(),
+			Parts:           parts,
+			TimesAddedCount: t.TimesAddedCount,
 		})
 	}
 	return trainingPhrases
@@ -164,6 +337,10 @@ func toTrainingPhrases(dialogflowTrainingPhrases []*dialogflowpb.Intent_Training
 func toDialogflowTrainingPhrases(trainingPhrases []TrainingPhrase) []*dialogflowpb.Intent_TrainingPhrase {
 	var dialogflowTrainingPhrases []*dialogflowpb.Intent_TrainingPhrase
 	for _, t := range trainingPhrases {
+		trainingPhraseType := dialogflowpb.Intent_TrainingPhrase_EXAMPLE
+		if val, ok := dialogflowpb.Intent_TrainingPhrase_Type_value[t.Type]; ok {
+			trainingPhraseType = dialogflowpb.Intent_TrainingPhrase_Type(val)
+		}
 		parts := make([]*dialogflowpb.Intent_TrainingPhrase_Part, len(t.Parts))
 		for i, p := range t.Parts {
 			parts[i] = &dialogflowpb.Intent_TrainingPhrase_Part{
@@ -174,32 +351,224 @@ func toDialogflowTrainingPhrases(trainingPhrases []TrainingPhrase) []*dialogflow
 			}
 		}
 		dialogflowTrainingPhrases = append(dialogflowTrainingPhrases, &dialogflowpb.Intent_TrainingPhrase{
-			Type:  dialogflowpb.Intent_TrainingPhrase_EXAMPLE,
-			Parts: parts,
+			Name:            t.Name,
+			Type:            trainingPhraseType,
+			Parts:           parts,
+			TimesAddedCount: t.TimesAddedCount,
 		})
 	}
 	return dialogflowTrainingPhrases
 }
 
+func toContexts(dialogflowContexts []*dialogflowpb.Context) []Context {
+	var contexts []Context
+	for _, c := range dialogflowContexts {
+		contexts = append(contexts, Context{
+			Name:          c.Name,
+			LifespanCount: c.LifespanCount,
+			Parameters:    structToMap(c.Parameters),
+		})
+	}
+	return contexts
+}
+
+func toDialogflowContexts(contexts []Context) []*dialogflowpb.Context {
+	var dialogflowContexts []*dialogflowpb.Context
+	for _, c := range contexts {
+		dialogflowContexts = append(dialogflowContexts, &dialogflowpb.Context{
+			Name:          c.Name,
+			LifespanCount: c.LifespanCount,
+			Parameters:    mapToStruct(c.Parameters),
+		})
+	}
+	return dialogflowContexts
+}
+
+func toMessages(dialogflowMessages []*dialogflowpb.Intent_Message) []Message {
+	var messages []Message
+	for _, m := range dialogflowMessages {
+		message := Message{Platform: m.Platform.String()}
+		switch msg := m.Message.(type) {
+		case *dialogflowpb.Intent_Message_Text_:
+			message.Text = msg.Text.GetText()
+		case *dialogflowpb.Intent_Message_Image_:
+			message.Image = toImage(msg.Image)
+		case *dialogflowpb.Intent_Message_QuickReplies_:
+			message.QuickReplies = &QuickReplies{
+				Title:        msg.QuickReplies.GetTitle(),
+				QuickReplies: msg.QuickReplies.GetQuickReplies(),
+			}
+		case *dialogflowpb.Intent_Message_Card_:
+			message.Card = &Card{
+				Title:    msg.Card.GetTitle(),
+				Subtitle: msg.Card.GetSubtitle(),
+				ImageURI: msg.Card.GetImageUri(),
+			}
+			for _, b := range msg.Card.GetButtons() {
+				message.Card.Buttons = append(message.Card.Buttons, CardButton{Text: b.Text, Postback: b.Postback})
+			}
+		case *dialogflowpb.Intent_Message_Payload:
+			message.Payload = structToMap(msg.Payload)
+			if message.Payload == nil {
+				message.Payload = make(map[string]interface{})
+			}
+		case *dialogflowpb.Intent_Message_SimpleResponses_:
+			message.SimpleResponses = []SimpleResponse{}
+			for _, r := range msg.SimpleResponses.GetSimpleResponses() {
+				message.SimpleResponses = append(message.SimpleResponses, SimpleResponse{
+					TextToSpeech: r.TextToSpeech,
+					SSML:         r.Ssml,
+					DisplayText:  r.DisplayText,
+				})
+			}
+		case *dialogflowpb.Intent_Message_BasicCard_:
+			message.BasicCard = &BasicCard{
+				Title:         msg.BasicCard.GetTitle(),
+				Subtitle:      msg.BasicCard.GetSubtitle(),
+				FormattedText: msg.BasicCard.GetFormattedText(),
+				Image:         toImage(msg.BasicCard.GetImage()),
+			}
+			for _, b := range msg.BasicCard.GetButtons() {
+				message.BasicCard.Buttons = append(message.BasicCard.Buttons, BasicCardButton{
+					Title: b.Title,
+					URI:   b.GetOpenUriAction().GetUri(),
+				})
+			}
+		case *dialogflowpb.Intent_Message_Suggestions_:
+			message.Suggestions = []string{}
+			for _, s := range msg.Suggestions.GetSuggestions() {
+				message.Suggestions = append(message.Suggestions, s.Title)
+			}
+		default:
+			message.unsupported = m
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func toImage(image *dialogflowpb.Intent_Message_Image) *Image {
+	if image == nil {
+		return nil
+	}
+	return &Image{ImageURI: image.ImageUri, AccessibilityText: image.AccessibilityText}
+}
+
 func toDialogflowIntentMessages(messages []Message) []*dialogflowpb.Intent_Message {
-	var messageTexts []string
+	var dialogflowMessages []*dialogflowpb.Intent_Message
 	for _, m := range messages {
-		messageTexts = append(messageTexts, m.Text)
+		dialogflowMessages = append(dialogflowMessages, toDialogflowIntentMessage(m))
 	}
-	intentMessageTexts := dialogflowpb.Intent_Message_Text{Text: messageTexts}
-	wrappedIntentMessageTexts := dialogflowpb.Intent_Message_Text_{Text: &intentMessageTexts}
-	intentMessage := dialogflowpb.Intent_Message{Message: &wrappedIntentMessageTexts}
-	return []*dialogflowpb.Intent_Message{&intentMessage}
+	return dialogflowMessages
+}
+
+func toDialogflowIntentMessage(m Message) *dialogflowpb.Intent_Message {
+	if m.unsupported != nil {
+		return m.unsupported
+	}
+	message := &dialogflowpb.Intent_Message{Platform: toDialogflowPlatform(m.Platform)}
+	switch {
+	case m.Image != nil:
+		message.Message = &dialogflowpb.Intent_Message_Image_{Image: toDialogflowImage(m.Image)}
+	case m.QuickReplies != nil:
+		message.Message = &dialogflowpb.Intent_Message_QuickReplies_{
+			QuickReplies: &dialogflowpb.Intent_Message_QuickReplies{
+				Title:        m.QuickReplies.Title,
+				QuickReplies: m.QuickReplies.QuickReplies,
+			},
+		}
+	case m.Card != nil:
+		card := &dialogflowpb.Intent_Message_Card{
+			Title:    m.Card.Title,
+			Subtitle: m.Card.Subtitle,
+			ImageUri: m.Card.ImageURI,
+		}
+		for _, b := range m.Card.Buttons {
+			card.Buttons = append(card.Buttons, &dialogflowpb.Intent_Message_Card_Button{Text: b.Text, Postback: b.Postback})
+		}
+		message.Message = &dialogflowpb.Intent_Message_Card_{Card: card}
+	case m.Payload != nil:
+		message.Message = &dialogflowpb.Intent_Message_Payload{Payload: mapToStruct(m.Payload)}
+	case m.SimpleResponses != nil:
+		simpleResponses := &dialogflowpb.Intent_Message_SimpleResponses{}
+		for _, r := range m.SimpleResponses {
+			simpleResponses.SimpleResponses = append(simpleResponses.SimpleResponses, &dialogflowpb.Intent_Message_SimpleResponse{
+				TextToSpeech: r.TextToSpeech,
+				Ssml:         r.SSML,
+				DisplayText:  r.DisplayText,
+			})
+		}
+		message.Message = &dialogflowpb.Intent_Message_SimpleResponses_{SimpleResponses: simpleResponses}
+	case m.BasicCard != nil:
+		basicCard := &dialogflowpb.Intent_Message_BasicCard{
+			Title:         m.BasicCard.Title,
+			Subtitle:      m.BasicCard.Subtitle,
+			FormattedText: m.BasicCard.FormattedText,
+			Image:         toDialogflowImage(m.BasicCard.Image),
+		}
+		for _, b := range m.BasicCard.Buttons {
+			basicCard.Buttons = append(basicCard.Buttons, &dialogflowpb.Intent_Message_BasicCard_Button{
+				Title:         b.Title,
+				OpenUriAction: &dialogflowpb.Intent_Message_BasicCard_Button_OpenUriAction{Uri: b.URI},
+			})
+		}
+		message.Message = &dialogflowpb.Intent_Message_BasicCard_{BasicCard: basicCard}
+	case m.Suggestions != nil:
+		suggestions := &dialogflowpb.Intent_Message_Suggestions{}
+		for _, s := range m.Suggestions {
+			suggestions.Suggestions = append(suggestions.Suggestions, &dialogflowpb.Intent_Message_Suggestion{Title: s})
+		}
+		message.Message = &dialogflowpb.Intent_Message_Suggestions_{Suggestions: suggestions}
+	default:
+		message.Message = &dialogflowpb.Intent_Message_Text_{
+			Text: &dialogflowpb.Intent_Message_Text{Text: m.Text},
+		}
+	}
+	return message
+}
+
+func toDialogflowImage(image *Image) *dialogflowpb.Intent_Message_Image {
+	if image == nil {
+		return nil
+	}
+	return &dialogflowpb.Intent_Message_Image{ImageUri: image.ImageURI, AccessibilityText: image.AccessibilityText}
+}
+
+func toDialogflowPlatform(platform string) dialogflowpb.Intent_Message_Platform {
+	if val, ok := dialogflowpb.Intent_Message_Platform_value[platform]; ok {
+		return dialogflowpb.Intent_Message_Platform(val)
+	}
+	return dialogflowpb.Intent_Message_PLATFORM_UNSPECIFIED
+}
+
+func toParameters(dialogflowParameters []*dialogflowpb.Intent_Parameter) []Parameter {
+	var parameters []Parameter
+	for _, p := range dialogflowParameters {
+		parameters = append(parameters, Parameter{
+			Name:                  p.Name,
+			DisplayName:           p.DisplayName,
+			Value:                 p.Value,
+			DefaultValue:          p.DefaultValue,
+			EntityTypeDisplayName: p.EntityTypeDisplayName,
+			Mandatory:             p.Mandatory,
+			Prompts:               p.Prompts,
+			IsList:                p.IsList,
+		})
+	}
+	return parameters
 }
 
 func toDialogflowParameters(parameters []Parameter) []*dialogflowpb.Intent_Parameter {
 	var dialogflowParameters []*dialogflowpb.Intent_Parameter
 	for _, p := range parameters {
 		dialogflowParameters = append(dialogflowParameters, &dialogflowpb.Intent_Parameter{
+			Name:                  p.Name,
 			DisplayName:           p.DisplayName,
-			EntityTypeDisplayName: p.EntityTypeDisplayName,
 			Value:                 p.Value,
+			DefaultValue:          p.DefaultValue,
+			EntityTypeDisplayName: p.EntityTypeDisplayName,
 			Mandatory:             p.Mandatory,
+			Prompts:               p.Prompts,
 			IsList:                p.IsList,
 		})
 	}
@@ -0,0 +1,325 @@
+package dialogflow
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+)
+
+func newTestDialogflowIntent() *dialogflowpb.Intent {
+	return &dialogflowpb.Intent{
+		Name:         "projects/example/agent/intents/2",
+		DisplayName:  "I am good",
+		WebhookState: dialogflowpb.Intent_WEBHOOK_STATE_ENABLED_FOR_SLOT_FILLING,
+		Priority:     500000,
+		IsFallback:   true,
+		MlDisabled:   true,
+		InputContextNames: []string{
+			"projects/example/agent/sessions/-/contexts/myname-followup",
+		},
+		Events: []string{"WELCOME"},
+		TrainingPhrases: []*dialogflowpb.Intent_TrainingPhrase{
+			{
+				Name: "b3d8a4c2-0000-0000-0000-000000000000",
+				Type: dialogflowpb.Intent_TrainingPhrase_EXAMPLE,
+				Parts: []*dialogflowpb.Intent_TrainingPhrase_Part{
+					{Text: "I am "},
+					{Text: "good", EntityType: "@mood", Alias: "mood", UserDefined: true},
+				},
+				TimesAddedCount: 2,
+			},
+		},
+		Action: "mood.good",
+		OutputContexts: []*dialogflowpb.Context{
+			{
+				Name:          "projects/example/agent/sessions/-/contexts/mood",
+				LifespanCount: 5,
+				Parameters: &structpb.Struct{
+					Fields: map[string]*structpb.Value{
+						"mood":  {Kind: &structpb.Value_StringValue{StringValue: "good"}},
+						"level": {Kind: &structpb.Value_NumberValue{NumberValue: 2}},
+						"tags": {Kind: &structpb.Value_ListValue{ListValue: &structpb.ListValue{
+							Values: []*structpb.Value{
+								{Kind: &structpb.Value_BoolValue{BoolValue: true}},
+							},
+						}}},
+					},
+				},
+			},
+		},
+		ResetContexts: true,
+		Parameters: []*dialogflowpb.Intent_Parameter{
+			{
+				Name:                  "a1b2c3",
+				DisplayName:           "mood",
+				Value:                 "$mood",
+				DefaultValue:          "#mood.mood",
+				EntityTypeDisplayName: "@mood",
+				Mandatory:             true,
+				Prompts:               []string{"How are you?"},
+				IsList:                true,
+			},
+		},
+		Messages: []*dialogflowpb.Intent_Message{
+			{
+				Message: &dialogflowpb.Intent_Message_Text_{
+					Text: &dialogflowpb.Intent_Message_Text{Text: []string{"Great", "Awesome"}},
+				},
+			},
+			{
+				Message: &dialogflowpb.Intent_Message_Text_{
+					Text: &dialogflowpb.Intent_Message_Text{Text: []string{"Great!"}},
+				},
+				Platform: dialogflowpb.Intent_Message_TELEGRAM,
+			},
+			{
+				Message: &dialogflowpb.Intent_Message_QuickReplies_{
+					QuickReplies: &dialogflowpb.Intent_Message_QuickReplies{
+						Title:        "How are you?",
+						QuickReplies: []string{"Good", "Bad"},
+					},
+				},
+				Platform: dialogflowpb.Intent_Message_TELEGRAM,
+			},
+			{
+				Message: &dialogflowpb.Intent_Message_Card_{
+					Card: &dialogflowpb.Intent_Message_Card{
+						Title:    "Good",
+						Subtitle: "Glad to hear",
+						ImageUri: "https://example.com/good.png",
+						Buttons: []*dialogflowpb.Intent_Message_Card_Button{
+							{Text: "Thanks", Postback: "thanks"},
+						},
+					},
+				},
+				Platform: dialogflowpb.Intent_Message_FACEBOOK,
+			},
+			{
+				Message: &dialogflowpb.Intent_Message_Image_{
+					Image: &dialogflowpb.Intent_Message_Image{
+						ImageUri:          "https://example.com/good.png",
+						AccessibilityText: "Thumbs up",
+					},
+				},
+				Platform: dialogflowpb.Intent_Message_SLACK,
+			},
+			{
+				Message: &dialogflowpb.Intent_Message_Payload{
+					Payload: &structpb.Struct{
+						Fields: map[string]*structpb.Value{
+							"text": {Kind: &structpb.Value_StringValue{StringValue: "*Great*"}},
+						},
+					},
+				},
+				Platform: dialogflowpb.Intent_Message_SLACK,
+			},
+			{
+				Message: &dialogflowpb.Intent_Message_SimpleResponses_{
+					SimpleResponses: &dialogflowpb.Intent_Message_SimpleResponses{
+						SimpleResponses: []*dialogflowpb.Intent_Message_SimpleResponse{
+							{TextToSpeech: "Great", DisplayText: "Great!"},
+						},
+					},
+				},
+				Platform: dialogflowpb.Intent_Message_ACTIONS_ON_GOOGLE,
+			},
+			{
+				Message: &dialogflowpb.Intent_Message_BasicCard_{
+					BasicCard: &dialogflowpb.Intent_Message_BasicCard{
+						Title:         "Good",
+						FormattedText: "*Glad* to hear",
+						Image: &dialogflowpb.Intent_Message_Image{
+							ImageUri:          "https://example.com/good.png",
+							AccessibilityText: "Thumbs up",
+						},
+						Buttons: []*dialogflowpb.Intent_Message_BasicCard_Button{
+							{
+								Title:         "More",
+								OpenUriAction: &dialogflowpb.Intent_Message_BasicCard_Button_OpenUriAction{Uri: "https://example.com"},
+							},
+						},
+					},
+				},
+				Platform: dialogflowpb.Intent_Message_ACTIONS_ON_GOOGLE,
+			},
+			{
+				Message: &dialogflowpb.Intent_Message_Suggestions_{
+					Suggestions: &dialogflowpb.Intent_Message_Suggestions{
+						Suggestions: []*dialogflowpb.Intent_Message_Suggestion{
+							{Title: "Thanks"},
+						},
+					},
+				},
+				Platform: dialogflowpb.Intent_Message_ACTIONS_ON_GOOGLE,
+			},
+		},
+		DefaultResponsePlatforms: []dialogflowpb.Intent_Message_Platform{
+			dialogflowpb.Intent_Message_TELEGRAM,
+		},
+		RootFollowupIntentName:   "projects/example/agent/intents/1",
+		ParentFollowupIntentName: "projects/example/agent/intents/1",
+		FollowupIntentInfo: []*dialogflowpb.Intent_FollowupIntentInfo{
+			{
+				FollowupIntentName:       "projects/example/agent/intents/3",
+				ParentFollowupIntentName: "projects/example/agent/intents/2",
+			},
+		},
+	}
+}
+
+func TestDialogflowIntentRoundTrip(t *testing.T) {
+	dialogflowIntent := newTestDialogflowIntent()
+
+	intent := dialogflowIntentToIntent(dialogflowIntent)
+
+	if !proto.Equal(dialogflowIntent, toDialogflowIntent(intent)) {
+		t.Errorf("expected %v, got %v", dialogflowIntent, toDialogflowIntent(intent))
+	}
+}
+
+func TestIntentRoundTrip(t *testing.T) {
+	intent := dialogflowIntentToIntent(newTestDialogflowIntent())
+
+	expected := Intent{
+		Name:              "projects/example/agent/intents/2",
+		DisplayName:       "I am good",
+		WebhookState:      "WEBHOOK_STATE_ENABLED_FOR_SLOT_FILLING",
+		Priority:          500000,
+		IsFallback:        true,
+		MlDisabled:        true,
+		InputContextNames: []string{"projects/example/agent/sessions/-/contexts/myname-followup"},
+		Events:            []string{"WELCOME"},
+		TrainingPhrases: []TrainingPhrase{
+			{
+				Name: "b3d8a4c2-0000-0000-0000-000000000000",
+				Type: "EXAMPLE",
+				Parts: []TrainingPhrasePart{
+					{Text: "I am "},
+					{Text: "good", EntityType: "@mood", Alias: "mood", UserDefined: true},
+				},
+				TimesAddedCount: 2,
+			},
+		},
+		Action: "mood.good",
+		OutputContexts: []Context{
+			{
+				Name:          "projects/example/agent/sessions/-/contexts/mood",
+				LifespanCount: 5,
+				Parameters: map[string]interface{}{
+					"mood":  "good",
+					"level": float64(2),
+					"tags":  []interface{}{true},
+				},
+			},
+		},
+		ResetContexts: true,
+		Parameters: []Parameter{
+			{
+				Name:                  "a1b2c3",
+				DisplayName:           "mood",
+				Value:                 "$mood",
+				DefaultValue:          "#mood.mood",
+				EntityTypeDisplayName: "@mood",
+				Mandatory:             true,
+				Prompts:               []string{"How are you?"},
+				IsList:                true,
+			},
+		},
+		Messages: []Message{
+			{Text: []string{"Great", "Awesome"}, Platform: "PLATFORM_UNSPECIFIED"},
+			{Text: []string{"Great!"}, Platform: "TELEGRAM"},
+			{
+				QuickReplies: &QuickReplies{Title: "How are you?", QuickReplies: []string{"Good", "Bad"}},
+				Platform:     "TELEGRAM",
+			},
+			{
+				Card: &Card{
+					Title:    "Good",
+					Subtitle: "Glad to hear",
+					ImageURI: "https://example.com/good.png",
+					Buttons:  []CardButton{{Text: "Thanks", Postback: "thanks"}},
+				},
+				Platform: "FACEBOOK",
+			},
+			{
+				Image:    &Image{ImageURI: "https://example.com/good.png", AccessibilityText: "Thumbs up"},
+				Platform: "SLACK",
+			},
+			{
+				Payload:  map[string]interface{}{"text": "*Great*"},
+				Platform: "SLACK",
+			},
+			{
+				SimpleResponses: []SimpleResponse{{TextToSpeech: "Great", DisplayText: "Great!"}},
+				Platform:        "ACTIONS_ON_GOOGLE",
+			},
+			{
+				BasicCard: &BasicCard{
+					Title:         "Good",
+					FormattedText: "*Glad* to hear",
+					Image:         &Image{ImageURI: "https://example.com/good.png", AccessibilityText: "Thumbs up"},
+					Buttons:       []BasicCardButton{{Title: "More", URI: "https://example.com"}},
+				},
+				Platform: "ACTIONS_ON_GOOGLE",
+			},
+			{
+				Suggestions: []string{"Thanks"},
+				Platform:    "ACTIONS_ON_GOOGLE",
+			},
+		},
+		DefaultResponsePlatforms: []string{"TELEGRAM"},
+		RootFollowupIntentName:   "projects/example/agent/intents/1",
+		ParentFollowupIntentName: "projects/example/agent/intents/1",
+		FollowupIntentInfo: []FollowupIntentInfo{
+			{
+				FollowupIntentName:       "projects/example/agent/intents/3",
+				ParentFollowupIntentName: "projects/example/agent/intents/2",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, intent) {
+		t.Errorf("expected %+v, got %+v", expected, intent)
+	}
+
+	if !reflect.DeepEqual(intent, dialogflowIntentToIntent(toDialogflowIntent(intent))) {
+		t.Errorf("expected %+v, got %+v", intent, dialogflowIntentToIntent(toDialogflowIntent(intent)))
+	}
+}
+
+func newTestListSelectIntent() *dialogflowpb.Intent {
+	return &dialogflowpb.Intent{
+		DisplayName: "Menu",
+		Messages: []*dialogflowpb.Intent_Message{
+			{
+				Message: &dialogflowpb.Intent_Message_ListSelect_{
+					ListSelect: &dialogflowpb.Intent_Message_ListSelect{
+						Title: "Pizzas",
+						Items: []*dialogflowpb.Intent_Message_ListSelect_Item{
+							{Info: &dialogflowpb.Intent_Message_SelectItemInfo{Key: "margherita"}, Title: "Margherita"},
+						},
+					},
+				},
+				Platform: dialogflowpb.Intent_Message_ACTIONS_ON_GOOGLE,
+			},
+		},
+	}
+}
+
+func TestUnsupportedMessageRoundTrip(t *testing.T) {
+	dialogflowIntent := newTestListSelectIntent()
+
+	intent := dialogflowIntentToIntent(dialogflowIntent)
+
+	if !proto.Equal(dialogflowIntent, toDialogflowIntent(intent)) {
+		t.Errorf("expected %v, got %v", dialogflowIntent, toDialogflowIntent(intent))
+	}
+
+	expected := []string{"google: list select message"}
+	if values := formatMessages(intent.Messages); !reflect.DeepEqual(expected, values) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
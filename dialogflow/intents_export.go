@@ -0,0 +1,216 @@
+package dialogflow
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+)
+
+type IntentsExporter interface {
+	ExportIntents(ctx context.Context) error
+}
+
+type intentsExporter struct {
+	intentsClient IntentsAPI
+	writer        io.Writer
+	languageCodes []string
+}
+
+// NewIntentsExporter returns an exporter of the intents in the default
+// language of the agent, with their translations in the languages.
+func NewIntentsExporter(intentsClient IntentsAPI, writer io.Writer, languageCodes ...string) IntentsExporter {
+	return &intentsExporter{
+		intentsClient: intentsClient,
+		writer:        writer,
+		languageCodes: languageCodes,
+	}
+}
+
+func (exporter *intentsExporter) ExportIntents(ctx context.Context) error {
+	intents, err := exporter.intentsClient.ListIntents(ctx, IntentViewFull, "")
+	if err != nil {
+		return fmt.Errorf("list intents: %v", err)
+	}
+
+	codes, err := languageCodes(exporter.languageCodes)
+	if err != nil {
+		return err
+	}
+	for _, languageCode := range codes {
+		translations, err := exporter.intentsClient.ListIntents(ctx, IntentViewFull, languageCode)
+		if err != nil {
+			return fmt.Errorf("list intents in language %q: %v", languageCode, err)
+		}
+		translated := make(map[string]Intent)
+		for _, translation := range translations {
+			translated[translation.Name] = translation
+		}
+		for i, intent := range intents {
+			if translation, ok := translated[intent.Name]; ok {
+				intents[i].Translations = append(intent.Translations, translation)
+			}
+		}
+	}
+
+	data, err := writeIntents(intents)
+	if err != nil {
+		return fmt.Errorf("write intents: %v", err)
+	}
+
+	if _, err = exporter.writer.Write(data); err != nil {
+		return fmt.Errorf("write data: %v", err)
+	}
+
+	return nil
+}
+
+// writeIntents writes the intents in the format read by readIntents. The
+// followup intents are nested below the intent they follow up on.
+func writeIntents(intents []Intent) ([]byte, error) {
+	names := make(map[string]bool)
+	for _, intent := range intents {
+		names[intent.Name] = true
+	}
+
+	followupIntents := make(map[string][]Intent)
+	var rootIntents []Intent
+	for _, intent := range intents {
+		if intent.ParentFollowupIntentName != "" && names[intent.ParentFollowupIntentName] {
+			followupIntents[intent.ParentFollowupIntentName] = append(followupIntents[intent.ParentFollowupIntentName], intent)
+			continue
+		}
+		rootIntents = append(rootIntents, intent)
+	}
+
+	// Messages that can not be written would be lost when the intents are
+	// applied again.
+	for _, intent := range intents {
+		for _, translation := range append([]Intent{intent}, intent.Translations...) {
+			for i, m := range translation.Messages {
+				if m.unsupported == nil {
+					continue
+				}
+				if translation.LanguageCode != "" {
+					return nil, fmt.Errorf("intent %q: language %q: message %d: %s messages are not supported", intent.DisplayName, translation.LanguageCode, i+1, messageKind(m.unsupported))
+				}
+				return nil, fmt.Errorf("intent %q: message %d: %s messages are not supported", intent.DisplayName, i+1, messageKind(m.unsupported))
+			}
+		}
+	}
+
+	var data intentsData
+	data.Intents = intentsToIntentData(rootIntents, followupIntents)
+
+	return yaml.Marshal(data)
+}
+
+// exportPriority leaves out the default priority.
+func exportPriority(priority int32) int32 {
+	if priority == DefaultIntentPriority {
+		return 0
+	}
+	return priority
+}
+
+func intentContextsData(intent Intent) *contextsData {
+	if len(intent.InputContextNames) == 0 && len(intent.OutputContexts) == 0 {
+		return nil
+	}
+	data := &contextsData{}
+	for _, name := range intent.InputContextNames {
+		data.In = append(data.In, shortContextName(name))
+	}
+	for _, c := range intent.OutputContexts {
+		outputContext := outputContextData{Name: shortContextName(c.Name)}
+		if c.LifespanCount != defaultContextLifespan {
+			lifespan := c.LifespanCount
+			outputContext.Lifespan = &lifespan
+		}
+		data.Out = append(data.Out, outputContext)
+	}
+	return data
+}
+
+func intentsToIntentData(intents []Intent, followupIntents map[string][]Intent) []intentData {
+	sort.SliceStable(intents, func(i, j int) bool {
+		return intents[i].DisplayName < intents[j].DisplayName
+	})
+
+	var data []intentData
+	for _, intent := range intents {
+		responses, messages := messagesToMessageData(intent.Messages)
+		data = append(data, intentData{
+			Name:            intent.DisplayName,
+			UserSays:        formatTrainingPhrases(intent.TrainingPhrases),
+			Responses:       responses,
+			Messages:        messages,
+			Contexts:        intentContextsData(intent),
+			Parameters:      parametersToParameterData(intent.Parameters, intent.TrainingPhrases),
+			FollowupIntents: intentsToIntentData(followupIntents[intent.Name], followupIntents),
+			IsFallback:      intent.IsFallback,
+
+			Action:             intent.Action,
+			Priority:           exportPriority(intent.Priority),
+			Events:             intent.Events,
+			Webhook:            toDialogflowWebhookState(intent.WebhookState) == dialogflowpb.Intent_WEBHOOK_STATE_ENABLED,
+			WebhookSlotFilling: toDialogflowWebhookState(intent.WebhookState) == dialogflowpb.Intent_WEBHOOK_STATE_ENABLED_FOR_SLOT_FILLING,
+			MlDisabled:         intent.MlDisabled,
+			ResetContexts:      intent.ResetContexts,
+
+			Languages: intentLanguagesData(intent.Translations),
+		})
+	}
+	return data
+}
+
+// messagesToMessageData returns the first text message for the default
+// platform as the responses, and all other messages as messages.
+func messagesToMessageData(intentMessages []Message) ([]string, []messageData) {
+	var (
+		responses []string
+		messages  []messageData
+	)
+	for i, m := range intentMessages {
+		if i == 0 && isDefaultTextMessage(m) && len(m.Text) > 0 {
+			responses = m.Text
+			continue
+		}
+		message := messageToMessageData(m)
+		if message.Text != nil && len(message.Text) == 0 {
+			continue
+		}
+		messages = append(messages, message)
+	}
+	return responses, messages
+}
+
+// intentLanguagesData returns the translations of an intent by language code,
+// leaving out the languages the intent is not translated to.
+func intentLanguagesData(translations []Intent) map[string]intentLanguageData {
+	var languages map[string]intentLanguageData
+	for _, translation := range translations {
+		data := intentLanguageData{UserSays: formatTrainingPhrases(translation.TrainingPhrases)}
+		data.Responses, data.Messages = messagesToMessageData(translation.Messages)
+		for _, p := range translation.Parameters {
+			if len(p.Prompts) == 0 {
+				continue
+			}
+			if data.Prompts == nil {
+				data.Prompts = make(map[string][]string)
+			}
+			data.Prompts[p.DisplayName] = p.Prompts
+		}
+		if data.UserSays == nil && data.Responses == nil && data.Messages == nil && data.Prompts == nil {
+			continue
+		}
+		if languages == nil {
+			languages = make(map[string]intentLanguageData)
+		}
+		languages[translation.LanguageCode] = data
+	}
+	return languages
+}
@@ -0,0 +1,103 @@
+package dialogflow
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWriteIntents(t *testing.T) {
+	data, err := ioutil.ReadFile("../examples/intents.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	intents, err := readIntents(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		remoteIntents []Intent
+		n             int
+	)
+	var flatten func(intents []Intent, parentFollowupIntentName string)
+	flatten = func(intents []Intent, parentFollowupIntentName string) {
+		for _, intent := range intents {
+			n++
+			intent.Name = fmt.Sprintf("projects/example/agent/intents/%d", n)
+			intent.ParentFollowupIntentName = parentFollowupIntentName
+			followupIntents := intent.FollowupIntents
+			intent.FollowupIntents = nil
+			remoteIntents = append(remoteIntents, intent)
+			flatten(followupIntents, intent.Name)
+		}
+	}
+	flatten(intents, "")
+
+	data, err = writeIntents(remoteIntents)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exportedIntents, err := readIntents(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Slice(intents, func(i, j int) bool {
+		return intents[i].DisplayName < intents[j].DisplayName
+	})
+
+	if !reflect.DeepEqual(intents, exportedIntents) {
+		t.Errorf("expected %+v, got %+v", intents, exportedIntents)
+	}
+}
+
+func TestWriteEntityTypes(t *testing.T) {
+	data, err := ioutil.ReadFile("../examples/entities.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entityTypes, err := readEntityTypes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = writeEntityTypes(entityTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exportedEntityTypes, err := readEntityTypes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entityTypes) != len(exportedEntityTypes) {
+		t.Fatalf("expected %d entity types, got %d", len(entityTypes), len(exportedEntityTypes))
+	}
+
+	exported := make(map[string]EntityType)
+	for _, entityType := range exportedEntityTypes {
+		exported[entityType.DisplayName] = entityType
+	}
+	for _, entityType := range entityTypes {
+		if !reflect.DeepEqual(entityType, exported[entityType.DisplayName]) {
+			t.Errorf("expected %+v, got %+v", entityType, exported[entityType.DisplayName])
+		}
+	}
+}
+
+func TestWriteIntentsUnsupportedMessage(t *testing.T) {
+	intents := []Intent{dialogflowIntentToIntent(newTestListSelectIntent())}
+
+	_, err := writeIntents(intents)
+	expected := `intent "Menu": message 1: list select messages are not supported`
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
@@ -1,42 +1,48 @@
 package dialogflow
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"regexp"
 
-	"github.com/ghodss/yaml"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
 )
 
 type IntentsImporter interface {
-	ImportIntents() error
+	ImportIntents(ctx context.Context) error
 }
 
 type intentsImporter struct {
-	intentsClient *IntentsClient
+	intentsClient IntentsAPI
 	source        Source
+	opts          importerOptions
 }
 
-func NewIntentsImporter(intentsClient *IntentsClient, source Source) IntentsImporter {
+func NewIntentsImporter(intentsClient IntentsAPI, source Source, opts ...ImporterOption) IntentsImporter {
 	return &intentsImporter{
 		intentsClient: intentsClient,
 		source:        source,
+		opts:          newImporterOptions(opts),
 	}
 }
 
-func (importer *intentsImporter) ImportIntents() error {
-	data, err := ioutil.ReadAll(importer.source)
+func (importer *intentsImporter) ImportIntents(ctx context.Context) error {
+	data, err := importer.source.ReadAll(ctx)
 	if err != nil {
 		return fmt.Errorf("read data: %v", err)
 	}
 
 	intents, err := readIntents(data)
 	if err != nil {
-		return fmt.Errorf("read intents: %v", err)
+		return fmt.Errorf("read intents: %v", sourceError(importer.source, err))
+	}
+
+	if err = checkLimits(intents, nil, importer.opts.limits); err != nil {
+		return err
 	}
 
 	for _, intent := range intents {
-		if err = importer.createIntent(intent); err != nil {
+		if err = importer.createIntent(ctx, intent); err != nil {
 			return err
 		}
 	}
@@ -44,14 +50,22 @@ func (importer *intentsImporter) ImportIntents() error {
 	return nil
 }
 
-func (importer *intentsImporter) createIntent(intent Intent) error {
-	newIntent, err := importer.intentsClient.CreateIntent(intent)
+func (importer *intentsImporter) createIntent(ctx context.Context, intent Intent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	newIntent, err := importer.intentsClient.CreateIntent(ctx, intent)
 	if err != nil {
 		return fmt.Errorf("create intent: %v", err)
 	}
 
+	if err = importer.translateIntent(ctx, intent, newIntent); err != nil {
+		return err
+	}
+
 	for _, followupIntent := range intent.FollowupIntents {
-		if err := importer.createFollowupIntent(followupIntent, newIntent); err != nil {
+		if err := importer.createFollowupIntent(ctx, followupIntent, newIntent); err != nil {
 			return err
 		}
 	}
@@ -59,15 +73,23 @@ func (importer *intentsImporter) createIntent(intent Intent) error {
 	return nil
 }
 
-func (importer *intentsImporter) createFollowupIntent(followupIntent Intent, parentFollowupIntent Intent) error {
-	parentFollowupIntent, err := importer.intentsClient.CreateFollowupIntent(followupIntent, parentFollowupIntent)
+func (importer *intentsImporter) createFollowupIntent(ctx context.Context, followupIntent Intent, parentFollowupIntent Intent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	parentFollowupIntent, err := importer.intentsClient.CreateFollowupIntent(ctx, followupIntent, parentFollowupIntent)
 	if err != nil {
 		return fmt.Errorf("create followup intent: %v", err)
 	}
 
+	if err = importer.translateIntent(ctx, followupIntent, parentFollowupIntent); err != nil {
+		return err
+	}
+
 	if len(followupIntent.FollowupIntents) > 0 {
 		for _, followupIntent := range followupIntent.FollowupIntents {
-			err := importer.createFollowupIntent(followupIntent, parentFollowupIntent)
+			err := importer.createFollowupIntent(ctx, followupIntent, parentFollowupIntent)
 			if err != nil {
 				return err
 			}
@@ -77,113 +99,202 @@ func (importer *intentsImporter) createFollowupIntent(followupIntent Intent, par
 	return nil
 }
 
+// translateIntent updates the created intent with the translations of the
+// intent in the selected languages.
+func (importer *intentsImporter) translateIntent(ctx context.Context, intent Intent, newIntent Intent) error {
+	for _, translation := range intent.Translations {
+		if !importer.opts.importLanguage(translation.LanguageCode) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, err := importer.intentsClient.UpdateIntent(ctx, translateIntent(newIntent, translation), "training_phrases", "messages", "parameters")
+		if err != nil {
+			return fmt.Errorf("update intent %q in language %q: %v", intent.DisplayName, translation.LanguageCode, err)
+		}
+	}
+	return nil
+}
+
 type intentData struct {
-	Name            string       `json:"name"`
-	UserSays        []string     `json:"usersays"`
-	Responses       []string     `json:"responses"`
-	FollowupIntents []intentData `json:"followup"`
-	IsFallback      bool         `json:"fallback"`
+	Name            string          `json:"name"`
+	UserSays        []string        `json:"usersays,omitempty"`
+	Responses       []string        `json:"responses,omitempty"`
+	Messages        []messageData   `json:"messages,omitempty"`
+	Contexts        *contextsData   `json:"contexts,omitempty"`
+	Parameters      []parameterData `json:"parameters,omitempty"`
+	FollowupIntents []intentData    `json:"followup,omitempty"`
+	IsFallback      bool            `json:"fallback,omitempty"`
+
+	Action             string   `json:"action,omitempty"`
+	Priority           int32    `json:"priority,omitempty"`
+	Events             []string `json:"events,omitempty"`
+	Webhook            bool     `json:"webhook,omitempty"`
+	WebhookSlotFilling bool     `json:"webhookSlotFilling,omitempty"`
+	MlDisabled         bool     `json:"mlDisabled,omitempty"`
+	ResetContexts      bool     `json:"resetContexts,omitempty"`
+
+	Languages map[string]intentLanguageData `json:"languages,omitempty"`
 }
 
-func readIntents(dat []byte) ([]Intent, error) {
-	var data struct {
-		Intents []intentData `json:"intents"`
+// contextsData holds the input and output contexts of an intent by their
+// short names, such as "myname-followup".
+type contextsData struct {
+	In  []string            `json:"in,omitempty"`
+	Out []outputContextData `json:"out,omitempty"`
+}
+
+// defaultContextLifespan is the lifespan of an output context without an
+// explicit lifespan, the same default as the Dialogflow console.
+const defaultContextLifespan = 5
+
+// outputContextData is an output context, written as its name when it has the
+// default lifespan.
+type outputContextData struct {
+	Name     string `json:"name"`
+	Lifespan *int32 `json:"lifespan,omitempty"`
+}
+
+func (data *outputContextData) UnmarshalJSON(b []byte) error {
+	var name string
+	if err := json.Unmarshal(b, &name); err == nil {
+		*data = outputContextData{Name: name}
+		return nil
 	}
+	type plain outputContextData
+	return json.Unmarshal(b, (*plain)(data))
+}
 
-	if err := yaml.Unmarshal(dat, &data); err != nil {
-		return nil, fmt.Errorf("unmarshal data: %v", err)
+func (data outputContextData) MarshalJSON() ([]byte, error) {
+	if data.Lifespan == nil {
+		return json.Marshal(data.Name)
+	}
+	type plain outputContextData
+	return json.Marshal(plain(data))
+}
+
+// intentsData is the YAML format of intents.
+type intentsData struct {
+	Intents []intentData `json:"intents"`
+}
+
+func readIntents(dat []byte) ([]Intent, error) {
+	var data intentsData
+	if err := decodeYAML(dat, &data); err != nil {
+		return nil, err
 	}
 
 	var intents []Intent
-	for _, intent := range data.Intents {
-		intents = append(intents, intentDataToIntent(intent))
+	for _, data := range data.Intents {
+		intent, err := intentDataToIntent(data)
+		if err != nil {
+			return nil, err
+		}
+		intents = append(intents, intent)
 	}
 
 	return intents, nil
 }
 
-func intentDataToIntent(intentData intentData) Intent {
+func intentDataToIntent(intentData intentData) (Intent, error) {
 	var messages []Message
-	for _, val := range intentData.Responses {
-		messages = append(messages, Message{Text: val})
+	if len(intentData.Responses) > 0 {
+		messages = append(messages, Message{Text: intentData.Responses})
+	}
+	for i, data := range intentData.Messages {
+		message, err := messageDataToMessage(data)
+		if err != nil {
+			return Intent{}, fmt.Errorf("intent %q: message %d: %v", intentData.Name, i+1, err)
+		}
+		messages = append(messages, message)
 	}
 
-	var (
-		parameters      []Parameter
-		trainingPhrases []TrainingPhrase
-		params          = make(map[string]bool)
-	)
-
+	var trainingPhrases []TrainingPhrase
 	for _, val := range intentData.UserSays {
-		parts := parseTrainingPhrase(val)
+		parts, err := parseTrainingPhrase(val)
+		if err != nil {
+			return Intent{}, fmt.Errorf("intent %q: training phrase %q: %v", intentData.Name, val, err)
+		}
 		trainingPhrases = append(trainingPhrases, TrainingPhrase{Parts: parts})
-		for _, p := range parts {
-			if p.Alias == "" {
-				continue
+	}
+
+	parameters, err := mergeParameters(trainingPhraseParameters(trainingPhrases), intentData.Parameters)
+	if err != nil {
+		return Intent{}, fmt.Errorf("intent %q: %v", intentData.Name, err)
+	}
+
+	var (
+		inputContextNames []string
+		outputContexts    []Context
+	)
+	if intentData.Contexts != nil {
+		for _, name := range intentData.Contexts.In {
+			if name == "" {
+				return Intent{}, fmt.Errorf("intent %q: input context name is empty", intentData.Name)
+			}
+			inputContextNames = append(inputContextNames, name)
+		}
+		for _, data := range intentData.Contexts.Out {
+			if data.Name == "" {
+				return Intent{}, fmt.Errorf("intent %q: output context name is empty", intentData.Name)
 			}
-			if _, ok := params[p.Alias]; ok {
-				continue
+			lifespan := int32(defaultContextLifespan)
+			if data.Lifespan != nil {
+				lifespan = *data.Lifespan
 			}
-			params[p.Alias] = true
-			parameters = append(parameters, Parameter{
-				DisplayName:           p.Alias,
-				Value:                 fmt.Sprintf("$%s", p.Alias),
-				EntityTypeDisplayName: p.EntityType,
-			})
+			if lifespan < 0 {
+				return Intent{}, fmt.Errorf("intent %q: output context %q: lifespan is negative", intentData.Name, data.Name)
+			}
+			outputContexts = append(outputContexts, Context{Name: data.Name, LifespanCount: lifespan})
 		}
 	}
 
 	var followupIntents []Intent
 	for _, val := range intentData.FollowupIntents {
-		followupIntents = append(followupIntents, intentDataToIntent(val))
+		followupIntent, err := intentDataToIntent(val)
+		if err != nil {
+			return Intent{}, err
+		}
+		followupIntents = append(followupIntents, followupIntent)
 	}
 
-	return Intent{
-		DisplayName:     intentData.Name,
-		IsFallback:      intentData.IsFallback,
-		TrainingPhrases: trainingPhrases,
-		Messages:        messages,
-		Parameters:      parameters,
-		FollowupIntents: followupIntents,
+	webhookState := dialogflowpb.Intent_WEBHOOK_STATE_UNSPECIFIED
+	switch {
+	case intentData.WebhookSlotFilling:
+		webhookState = dialogflowpb.Intent_WEBHOOK_STATE_ENABLED_FOR_SLOT_FILLING
+	case intentData.Webhook:
+		webhookState = dialogflowpb.Intent_WEBHOOK_STATE_ENABLED
 	}
-}
 
-var trainingPhraseRegexp = regexp.MustCompile(`@(\w+):(?:(\w+)|['"]([^'"]+)['"])`)
-
-func parseTrainingPhrase(trainingPhrase string) []TrainingPhrasePart {
-	var parts []TrainingPhrasePart
-
-	matches := trainingPhraseRegexp.FindAllStringSubmatchIndex(trainingPhrase, -1)
-
-	var n int
-	for _, match := range matches {
-		if match[0] > n {
-			parts = append(parts, TrainingPhrasePart{
-				Text: trainingPhrase[n:match[0]],
-			})
-		}
-
-		var text string
-		if match[4] >= 0 && match[5] >= 0 {
-			text = trainingPhrase[match[4]:match[5]]
-		} else {
-			text = trainingPhrase[match[6]:match[7]]
+	for _, event := range intentData.Events {
+		if event == "" {
+			return Intent{}, fmt.Errorf("intent %q: event name is empty", intentData.Name)
 		}
+	}
 
-		parts = append(parts, TrainingPhrasePart{
-			Text:        text,
-			EntityType:  trainingPhrase[match[0]:match[3]],
-			Alias:       trainingPhrase[match[2]:match[3]],
-			UserDefined: true,
-		})
-		n = match[1]
+	intent := Intent{
+		DisplayName:       intentData.Name,
+		WebhookState:      webhookState.String(),
+		Priority:          intentData.Priority,
+		IsFallback:        intentData.IsFallback,
+		MlDisabled:        intentData.MlDisabled,
+		Action:            intentData.Action,
+		Events:            intentData.Events,
+		ResetContexts:     intentData.ResetContexts,
+		TrainingPhrases:   trainingPhrases,
+		Messages:          messages,
+		Parameters:        parameters,
+		InputContextNames: inputContextNames,
+		OutputContexts:    outputContexts,
+		FollowupIntents:   followupIntents,
 	}
 
-	if n < len(trainingPhrase) {
-		parts = append(parts, TrainingPhrasePart{
-			Text: trainingPhrase[n:],
-		})
+	translations, err := intentTranslations(intent, intentData.Languages)
+	if err != nil {
+		return Intent{}, fmt.Errorf("intent %q: %v", intentData.Name, err)
 	}
+	intent.Translations = translations
 
-	return parts
+	return intent, nil
 }
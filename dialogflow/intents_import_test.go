@@ -8,7 +8,10 @@ import (
 func TestParseTrainingPhraseParts(t *testing.T) {
 	trainingPhrase := `Lorem ipsum @entity:dolor sit amet, @anotherentity:'consectetur adipiscing' elit.`
 
-	parts := parseTrainingPhrase(trainingPhrase)
+	parts, err := parseTrainingPhrase(trainingPhrase)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	expected := []TrainingPhrasePart{
 		{
@@ -38,3 +41,186 @@ func TestParseTrainingPhraseParts(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestReadIntentsMessages(t *testing.T) {
+	data := []byte(`
+intents:
+  - name: Hello
+    responses:
+      - Hi
+    messages:
+      - platform: telegram
+        text:
+          - Hi!
+      - platform: facebook
+        card:
+          title: Hello
+          buttons:
+            - text: Hi
+              postback: hi
+      - platform: google
+        basicCard:
+          title: Hello
+          image:
+            url: https://example.com/hello.png
+`)
+
+	intents, err := readIntents(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Message{
+		{Text: []string{"Hi"}},
+		{Text: []string{"Hi!"}, Platform: "TELEGRAM"},
+		{
+			Card:     &Card{Title: "Hello", Buttons: []CardButton{{Text: "Hi", Postback: "hi"}}},
+			Platform: "FACEBOOK",
+		},
+		{
+			BasicCard: &BasicCard{Title: "Hello", Image: &Image{ImageURI: "https://example.com/hello.png"}},
+			Platform:  "ACTIONS_ON_GOOGLE",
+		},
+	}
+
+	if !reflect.DeepEqual(expected, intents[0].Messages) {
+		t.Errorf("expected %+v, got %+v", expected, intents[0].Messages)
+	}
+}
+
+func TestReadIntentsInvalidMessages(t *testing.T) {
+	tests := []string{
+		"{platform: telegram}",
+		"{text: [Hi], suggestions: [Hello]}",
+		"{platform: myspace, text: [Hi]}",
+	}
+
+	for _, test := range tests {
+		data := []byte("intents:\n  - name: Hello\n    messages:\n      - " + test + "\n")
+		if _, err := readIntents(data); err == nil {
+			t.Errorf("expected error for message %s", test)
+		}
+	}
+}
+
+func TestReadIntentsParameters(t *testing.T) {
+	data := []byte(`
+intents:
+  - name: Order pizza
+    usersays:
+      - I want a @size:large pizza
+    parameters:
+      - name: size
+        mandatory: true
+        prompts:
+          - What size?
+      - name: toppings
+        entity: '@topping'
+        default: '#order.toppings'
+        list: true
+`)
+
+	intents, err := readIntents(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Parameter{
+		{
+			DisplayName:           "size",
+			Value:                 "$size",
+			EntityTypeDisplayName: "@size",
+			Mandatory:             true,
+			Prompts:               []string{"What size?"},
+		},
+		{
+			DisplayName:           "toppings",
+			Value:                 "$toppings",
+			DefaultValue:          "#order.toppings",
+			EntityTypeDisplayName: "@topping",
+			IsList:                true,
+		},
+	}
+
+	if !reflect.DeepEqual(expected, intents[0].Parameters) {
+		t.Errorf("expected %+v, got %+v", expected, intents[0].Parameters)
+	}
+}
+
+func TestReadIntentsParameterEntity(t *testing.T) {
+	for _, entity := range []string{"'@topping'", "topping"} {
+		data := []byte("intents:\n  - name: Hello\n    parameters:\n      - {name: toppings, entity: " + entity + "}\n")
+		intents, err := readIntents(data)
+		if err != nil {
+			t.Errorf("%s: %v", entity, err)
+			continue
+		}
+		if actual := intents[0].Parameters[0].EntityTypeDisplayName; actual != "@topping" {
+			t.Errorf("%s: expected @topping, got %s", entity, actual)
+		}
+	}
+}
+
+func TestReadIntentsInvalidParameters(t *testing.T) {
+	tests := []string{
+		"{entity: '@size'}",
+		"{name: size, mandatory: true}",
+		"{name: toppings}",
+	}
+
+	for _, test := range tests {
+		data := []byte("intents:\n  - name: Hello\n    usersays:\n      - A @size:large pizza\n    parameters:\n      - " + test + "\n")
+		if _, err := readIntents(data); err == nil {
+			t.Errorf("expected error for parameter %s", test)
+		}
+	}
+}
+
+func TestReadIntentsMetadata(t *testing.T) {
+	data := []byte(`
+intents:
+  - name: Welcome
+    action: input.welcome
+    priority: 750000
+    events:
+      - WELCOME
+    webhookSlotFilling: true
+    mlDisabled: true
+    resetContexts: true
+`)
+
+	intents, err := readIntents(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Intent{
+		DisplayName:   "Welcome",
+		WebhookState:  "WEBHOOK_STATE_ENABLED_FOR_SLOT_FILLING",
+		Priority:      750000,
+		MlDisabled:    true,
+		Action:        "input.welcome",
+		Events:        []string{"WELCOME"},
+		ResetContexts: true,
+	}
+
+	if !reflect.DeepEqual(expected, intents[0]) {
+		t.Errorf("expected %+v, got %+v", expected, intents[0])
+	}
+}
+
+func TestReadIntentsInvalidLanguages(t *testing.T) {
+	tests := []string{
+		"German: {usersays: [Hallo]}",
+		"de: {usersays: ['Eine @size:kind=große Pizza']}",
+		"de: {prompts: {toppings: [Welche Beläge?]}}",
+		"de: {usersays: [Eine Pizza]}",
+	}
+
+	for _, test := range tests {
+		data := []byte("intents:\n  - name: Order\n    usersays:\n      - A @size:large pizza\n    parameters:\n      - {name: size, mandatory: true, prompts: [What size?]}\n    languages:\n      " + test + "\n")
+		if _, err := readIntents(data); err == nil {
+			t.Errorf("expected error for language %s", test)
+		}
+	}
+}
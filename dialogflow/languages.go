@@ -0,0 +1,258 @@
+package dialogflow
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+)
+
+// languageCodeRegexp matches a language code such as "de" or "pt-BR".
+var languageCodeRegexp = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]+)*$`)
+
+type importerOptions struct {
+	languages map[string]bool
+	limits    Limits
+}
+
+// ImporterOption configures an importer.
+type ImporterOption func(*importerOptions)
+
+// WithLanguages limits the translations that are imported to the languages.
+// The default language of the agent is always imported.
+func WithLanguages(languageCodes ...string) ImporterOption {
+	return func(opts *importerOptions) {
+		if len(languageCodes) == 0 {
+			return
+		}
+		opts.languages = make(map[string]bool)
+		for _, languageCode := range languageCodes {
+			opts.languages[languageCode] = true
+		}
+	}
+}
+
+// WithLimits sets the limits the intents or entity types are checked against
+// before they are imported, DefaultLimits by default.
+func WithLimits(limits Limits) ImporterOption {
+	return func(opts *importerOptions) {
+		opts.limits = limits
+	}
+}
+
+func newImporterOptions(opts []ImporterOption) importerOptions {
+	options := importerOptions{limits: DefaultLimits}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// importLanguage reports whether the translation in the language is imported.
+func (opts importerOptions) importLanguage(languageCode string) bool {
+	return opts.languages == nil || opts.languages[languageCode]
+}
+
+// intentLanguageData holds the training phrases, responses and parameter
+// prompts of an intent in another language.
+type intentLanguageData struct {
+	UserSays  []string            `json:"usersays,omitempty"`
+	Responses []string            `json:"responses,omitempty"`
+	Messages  []messageData       `json:"messages,omitempty"`
+	Prompts   map[string][]string `json:"prompts,omitempty"`
+}
+
+// entityTypeLanguageData holds the values of an entity type in another
+// language.
+type entityTypeLanguageData struct {
+	Values []entityData `json:"values"`
+}
+
+// languageCodes returns the language codes of the languages in order.
+func languageCodes(languages []string) ([]string, error) {
+	sort.Strings(languages)
+	for _, languageCode := range languages {
+		if !languageCodeRegexp.MatchString(languageCode) {
+			return nil, fmt.Errorf("invalid language code %q", languageCode)
+		}
+	}
+	return languages, nil
+}
+
+// intentTranslations returns the translations of the intent in the languages.
+func intentTranslations(intent Intent, languages map[string]intentLanguageData) ([]Intent, error) {
+	var codes []string
+	for languageCode := range languages {
+		codes = append(codes, languageCode)
+	}
+	codes, err := languageCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	var translations []Intent
+	for _, languageCode := range codes {
+		translation, err := intentTranslation(intent, languages[languageCode])
+		if err != nil {
+			return nil, fmt.Errorf("language %q: %v", languageCode, err)
+		}
+		translation.LanguageCode = languageCode
+		translations = append(translations, translation)
+	}
+	return translations, nil
+}
+
+func intentTranslation(intent Intent, data intentLanguageData) (Intent, error) {
+	parameters := make(map[string]bool)
+	for _, p := range intent.Parameters {
+		parameters[p.DisplayName] = true
+	}
+
+	var trainingPhrases []TrainingPhrase
+	for _, val := range data.UserSays {
+		parts, err := parseTrainingPhrase(val)
+		if err != nil {
+			return Intent{}, fmt.Errorf("training phrase %q: %v", val, err)
+		}
+		for _, p := range parts {
+			if p.Alias != "" && !parameters[p.Alias] {
+				return Intent{}, fmt.Errorf("training phrase %q: unknown parameter %q", val, p.Alias)
+			}
+		}
+		trainingPhrases = append(trainingPhrases, TrainingPhrase{Parts: parts})
+	}
+
+	var messages []Message
+	if len(data.Responses) > 0 {
+		messages = append(messages, Message{Text: data.Responses})
+	}
+	for i, d := range data.Messages {
+		message, err := messageDataToMessage(d)
+		if err != nil {
+			return Intent{}, fmt.Errorf("message %d: %v", i+1, err)
+		}
+		messages = append(messages, message)
+	}
+
+	for name := range data.Prompts {
+		if !parameters[name] {
+			return Intent{}, fmt.Errorf("prompts of unknown parameter %q", name)
+		}
+	}
+
+	var translatedParameters []Parameter
+	for _, p := range intent.Parameters {
+		prompts := data.Prompts[p.DisplayName]
+		if p.Mandatory && len(prompts) == 0 {
+			return Intent{}, fmt.Errorf("mandatory parameter %q has no prompts", p.DisplayName)
+		}
+		p.Prompts = prompts
+		translatedParameters = append(translatedParameters, p)
+	}
+
+	return Intent{
+		TrainingPhrases: trainingPhrases,
+		Messages:        messages,
+		Parameters:      translatedParameters,
+	}, nil
+}
+
+// entityTypeTranslations returns the translations of the entity type in the
+// languages.
+func entityTypeTranslations(entityType EntityType, languages map[string]entityTypeLanguageData) ([]EntityType, error) {
+	var codes []string
+	for languageCode := range languages {
+		codes = append(codes, languageCode)
+	}
+	codes, err := languageCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+
+	var translations []EntityType
+	for _, languageCode := range codes {
+		var entities []Entity
+		for _, val := range languages[languageCode].Values {
+			if val.Value == "" {
+				return nil, fmt.Errorf("language %q: entity value is empty", languageCode)
+			}
+			if len(val.Synonyms) > 0 && entityType.Kind != dialogflowpb.EntityType_KIND_MAP.String() {
+				return nil, fmt.Errorf("language %q: only map entity types have synonyms", languageCode)
+			}
+			entities = append(entities, Entity{Value: val.Value, Synonyms: val.Synonyms})
+		}
+		translation := EntityType{
+			DisplayName:  entityType.DisplayName,
+			Kind:         entityType.Kind,
+			Entities:     entities,
+			LanguageCode: languageCode,
+		}
+		if err := checkEntityTypeValues(translation); err != nil {
+			return nil, fmt.Errorf("language %q: %v", languageCode, err)
+		}
+		translations = append(translations, translation)
+	}
+	return translations, nil
+}
+
+// translateIntent returns the intent with the training phrases, messages and
+// parameter prompts of the translation.
+func translateIntent(intent Intent, translation Intent) Intent {
+	prompts := make(map[string][]string)
+	for _, p := range translation.Parameters {
+		prompts[p.DisplayName] = p.Prompts
+	}
+
+	intent.LanguageCode = translation.LanguageCode
+	intent.TrainingPhrases = translation.TrainingPhrases
+	intent.Messages = translation.Messages
+	parameters := make([]Parameter, len(intent.Parameters))
+	for i, p := range intent.Parameters {
+		p.Prompts = prompts[p.DisplayName]
+		parameters[i] = p
+	}
+	intent.Parameters = parameters
+	intent.Translations = nil
+	intent.FollowupIntents = nil
+
+	return intent
+}
+
+// intentLanguageCodes returns the language codes of the translations of the
+// intents and their followup intents, in order.
+func intentLanguageCodes(intents []Intent) []string {
+	seen := make(map[string]bool)
+	var walk func(intents []Intent)
+	walk = func(intents []Intent) {
+		for _, intent := range intents {
+			for _, translation := range intent.Translations {
+				seen[translation.LanguageCode] = true
+			}
+			walk(intent.FollowupIntents)
+		}
+	}
+	walk(intents)
+	return sortedKeys(seen)
+}
+
+// entityTypeLanguageCodes returns the language codes of the translations of
+// the entity types, in order.
+func entityTypeLanguageCodes(entityTypes []EntityType) []string {
+	seen := make(map[string]bool)
+	for _, entityType := range entityTypes {
+		for _, translation := range entityType.Translations {
+			seen[translation.LanguageCode] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
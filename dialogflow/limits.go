@@ -0,0 +1,136 @@
+package dialogflow
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits are the limits Dialogflow puts on intents and entity types. A limit
+// of 0 is not checked.
+type Limits struct {
+	MaxDisplayNameLength int
+	MaxTrainingPhrases   int
+	MaxEntities          int
+	MaxResponseLength    int
+	MaxFollowupDepth     int
+	// ParameterName matches the names that parameters may have, or is nil
+	// to allow any name.
+	ParameterName *regexp.Regexp
+}
+
+// DefaultLimits are the documented limits of Dialogflow ES.
+var DefaultLimits = Limits{
+	MaxDisplayNameLength: 100,
+	MaxTrainingPhrases:   2000,
+	MaxEntities:          30000,
+	MaxResponseLength:    4000,
+	MaxFollowupDepth:     10,
+	ParameterName:        regexp.MustCompile(`^[A-Za-z0-9_-]+$`),
+}
+
+type LimitViolation struct {
+	Limit   string `json:"limit"`
+	Message string `json:"message"`
+}
+
+func (violation LimitViolation) String() string {
+	return fmt.Sprintf("%s: %s", violation.Limit, violation.Message)
+}
+
+// LimitsError is the error for the limit violations of intents and entity
+// types.
+type LimitsError []LimitViolation
+
+func (err LimitsError) Error() string {
+	lines := make([]string, len(err))
+	for i, violation := range err {
+		lines[i] = violation.String()
+	}
+	return fmt.Sprintf("%d limit violations:\n%s", len(err), strings.Join(lines, "\n"))
+}
+
+// CheckLimits returns every violation of the limits by the intents, their
+// followup intents and translations, and the entity types.
+func CheckLimits(intents []Intent, entityTypes []EntityType, limits Limits) []LimitViolation {
+	c := &limitsChecker{limits: limits}
+	for _, entityType := range entityTypes {
+		c.checkEntityType(entityType)
+	}
+	for _, intent := range intents {
+		c.checkIntent(intent, 0)
+	}
+	return c.violations
+}
+
+// checkLimits returns a LimitsError for the violations of the limits, or nil.
+func checkLimits(intents []Intent, entityTypes []EntityType, limits Limits) error {
+	if violations := CheckLimits(intents, entityTypes, limits); len(violations) > 0 {
+		return LimitsError(violations)
+	}
+	return nil
+}
+
+type limitsChecker struct {
+	limits     Limits
+	violations []LimitViolation
+}
+
+func (c *limitsChecker) report(limit, format string, args ...interface{}) {
+	c.violations = append(c.violations, LimitViolation{Limit: limit, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *limitsChecker) checkDisplayName(kind, name string) {
+	if max := c.limits.MaxDisplayNameLength; max > 0 && utf8.RuneCountInString(name) > max {
+		c.report("display-name-length", "%s %q: display name has %d characters, more than %d", kind, name, utf8.RuneCountInString(name), max)
+	}
+}
+
+func (c *limitsChecker) checkEntityType(entityType EntityType) {
+	c.checkDisplayName("entity type", entityType.DisplayName)
+
+	for _, e := range append([]EntityType{entityType}, entityType.Translations...) {
+		if max := c.limits.MaxEntities; max > 0 && len(e.Entities) > max {
+			c.report("entities", "entity type %q%s: %d entities, more than %d", entityType.DisplayName, inLanguage(e.LanguageCode), len(e.Entities), max)
+		}
+	}
+}
+
+func (c *limitsChecker) checkIntent(intent Intent, depth int) {
+	c.checkDisplayName("intent", intent.DisplayName)
+
+	if max := c.limits.MaxFollowupDepth; max > 0 && depth > max {
+		c.report("followup-depth", "intent %q: followup intent at depth %d, more than %d", intent.DisplayName, depth, max)
+	}
+
+	for _, p := range intent.Parameters {
+		if c.limits.ParameterName != nil && !c.limits.ParameterName.MatchString(p.DisplayName) {
+			c.report("parameter-name", "intent %q: parameter name %q does not match %s", intent.DisplayName, p.DisplayName, c.limits.ParameterName)
+		}
+	}
+
+	for _, i := range append([]Intent{intent}, intent.Translations...) {
+		if max := c.limits.MaxTrainingPhrases; max > 0 && len(i.TrainingPhrases) > max {
+			c.report("training-phrases", "intent %q%s: %d training phrases, more than %d", intent.DisplayName, inLanguage(i.LanguageCode), len(i.TrainingPhrases), max)
+		}
+		for _, m := range i.Messages {
+			for _, text := range m.Text {
+				if max := c.limits.MaxResponseLength; max > 0 && utf8.RuneCountInString(text) > max {
+					c.report("response-length", "intent %q%s: response has %d characters, more than %d", intent.DisplayName, inLanguage(i.LanguageCode), utf8.RuneCountInString(text), max)
+				}
+			}
+		}
+	}
+
+	for _, followupIntent := range intent.FollowupIntents {
+		c.checkIntent(followupIntent, depth+1)
+	}
+}
+
+func inLanguage(languageCode string) string {
+	if languageCode == "" {
+		return ""
+	}
+	return fmt.Sprintf(" in language %q", languageCode)
+}
@@ -0,0 +1,77 @@
+package dialogflow
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestCheckLimits(t *testing.T) {
+	intents, err := readIntents([]byte(`
+intents:
+  - name: Order a pizza with extra cheese
+    usersays:
+      - A @size:large pizza
+      - A @size:small pizza
+      - A @sys.number:number_of=two pizzas
+    responses:
+      - Your pizza is on its way to you
+    followup:
+      - name: Order more
+        usersays:
+          - More
+        followup:
+          - name: Order even more
+            usersays:
+              - Even more
+    languages:
+      de:
+        usersays:
+          - Eine @size:große Pizza
+        responses:
+          - Deine Pizza ist unterwegs zu dir
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entityTypes, err := readEntityTypes([]byte(`
+entities:
+  - type: size
+    values: [small, medium, large]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limits := Limits{
+		MaxDisplayNameLength: 20,
+		MaxTrainingPhrases:   2,
+		MaxEntities:          2,
+		MaxResponseLength:    30,
+		MaxFollowupDepth:     1,
+		ParameterName:        regexp.MustCompile(`^[a-z]+$`),
+	}
+
+	var violations []string
+	for _, violation := range CheckLimits(intents, entityTypes, limits) {
+		violations = append(violations, violation.String())
+	}
+
+	expected := []string{
+		`entities: entity type "size": 3 entities, more than 2`,
+		`display-name-length: intent "Order a pizza with extra cheese": display name has 31 characters, more than 20`,
+		`parameter-name: intent "Order a pizza with extra cheese": parameter name "number_of" does not match ^[a-z]+$`,
+		`training-phrases: intent "Order a pizza with extra cheese": 3 training phrases, more than 2`,
+		`response-length: intent "Order a pizza with extra cheese": response has 31 characters, more than 30`,
+		`response-length: intent "Order a pizza with extra cheese" in language "de": response has 32 characters, more than 30`,
+		`followup-depth: intent "Order even more": followup intent at depth 2, more than 1`,
+	}
+
+	if !reflect.DeepEqual(expected, violations) {
+		t.Errorf("expected %q, got %q", expected, violations)
+	}
+
+	if violations := CheckLimits(intents, entityTypes, DefaultLimits); len(violations) > 0 {
+		t.Errorf("expected no violations of the default limits, got %v", violations)
+	}
+}
@@ -0,0 +1,370 @@
+package dialogflow
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type LintRule string
+
+const (
+	LintRuleUnknownEntityType LintRule = "unknown-entity-type"
+	LintRuleUnknownParameter  LintRule = "unknown-parameter"
+	LintRuleDuplicateName     LintRule = "duplicate-name"
+	LintRuleDuplicatePhrase   LintRule = "duplicate-phrase"
+	LintRuleEmptyIntent       LintRule = "empty-intent"
+	LintRuleUnusedEntityType  LintRule = "unused-entity-type"
+	LintRuleLimit             LintRule = "limit"
+	LintRuleInvalid           LintRule = "invalid"
+)
+
+type LintProblem struct {
+	Rule    LintRule `json:"rule"`
+	Message string   `json:"message"`
+}
+
+func (problem LintProblem) String() string {
+	return fmt.Sprintf("%s: %s", problem.Rule, problem.Message)
+}
+
+type Linter interface {
+	Lint(ctx context.Context) ([]LintProblem, error)
+}
+
+type linter struct {
+	intentsSource     Source
+	entityTypesSource Source
+	limits            Limits
+}
+
+// NewLinter returns a linter for the intents and entities of the sources,
+// which also reports violations of the limits and the intents and entities
+// that cannot be read. A nil source skips the intents or entities, and without
+// the entities only the system entity types are known.
+func NewLinter(intentsSource, entityTypesSource Source, limits Limits) Linter {
+	return &linter{
+		intentsSource:     intentsSource,
+		entityTypesSource: entityTypesSource,
+		limits:            limits,
+	}
+}
+
+func (linter *linter) Lint(ctx context.Context) ([]LintProblem, error) {
+	var (
+		l               = &lint{}
+		intents         []Intent
+		entityTypes     []EntityType
+		intentsRead     bool
+		entityTypesRead bool
+	)
+	if linter.entityTypesSource != nil {
+		data, ok := l.read(ctx, linter.entityTypesSource)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if ok {
+			entityTypes, entityTypesRead = l.readEntityTypes(linter.entityTypesSource, data)
+		}
+	}
+	if linter.intentsSource != nil {
+		data, ok := l.read(ctx, linter.intentsSource)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if ok {
+			intents, intentsRead = l.readIntents(linter.intentsSource, data)
+		}
+	}
+
+	for _, violation := range CheckLimits(intents, entityTypes, linter.limits) {
+		l.report(LintRuleLimit, "%s", violation)
+	}
+	intents = flattenIntents(intents)
+	l.checkDisplayNames(intents, entityTypes)
+	l.checkIntents(intents)
+	l.checkDuplicatePhrases(intents)
+	if intentsRead && entityTypesRead {
+		l.checkEntityTypeUsage(intents, entityTypes)
+	} else {
+		l.checkSystemEntityTypes(intents)
+	}
+
+	return l.problems, nil
+}
+
+type lint struct {
+	problems []LintProblem
+}
+
+func (l *lint) report(rule LintRule, format string, args ...interface{}) {
+	l.problems = append(l.problems, LintProblem{Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// read reads all data of the source, reporting the error if it cannot be read.
+func (l *lint) read(ctx context.Context, source Source) ([]byte, bool) {
+	data, err := source.ReadAll(ctx)
+	if err != nil {
+		l.report(LintRuleInvalid, "%s: %v", source.Name(), err)
+		return nil, false
+	}
+	return data, true
+}
+
+// readIntents reads the intents of the data, reporting every intent that
+// cannot be read at the line where it starts, and reports whether all intents
+// were read.
+func (l *lint) readIntents(source Source, dat []byte) ([]Intent, bool) {
+	var data intentsData
+	if err := decodeYAML(dat, &data); err != nil {
+		l.report(LintRuleInvalid, "%v", sourceError(source, err))
+		return nil, false
+	}
+
+	lines := yamlListLines(dat, "intents")
+	var intents []Intent
+	for i, data := range data.Intents {
+		intent, err := intentDataToIntent(data)
+		if err != nil {
+			l.report(LintRuleInvalid, "%v", sourceError(source, lineError(lines, i, err)))
+			continue
+		}
+		intents = append(intents, intent)
+	}
+	return intents, len(intents) == len(data.Intents)
+}
+
+// readEntityTypes reads the entity types of the data, reporting every entity
+// type that cannot be read at the line where it starts, and reports whether
+// all entity types were read.
+func (l *lint) readEntityTypes(source Source, dat []byte) ([]EntityType, bool) {
+	var data entityTypesData
+	if err := decodeYAML(dat, &data); err != nil {
+		l.report(LintRuleInvalid, "%v", sourceError(source, err))
+		return nil, false
+	}
+
+	lines := yamlListLines(dat, "entities")
+	var entityTypes []EntityType
+	for i, data := range data.EntityTypes {
+		entityType, err := entityTypeDataToEntityType(data)
+		if err != nil {
+			err = fmt.Errorf("entity type %q: %v", data.EntityType, err)
+			l.report(LintRuleInvalid, "%v", sourceError(source, lineError(lines, i, err)))
+			continue
+		}
+		entityTypes = append(entityTypes, entityType)
+	}
+	if _, err := sortEntityTypes(entityTypes); err != nil {
+		l.report(LintRuleInvalid, "%v", sourceError(source, err))
+	}
+	return entityTypes, len(entityTypes) == len(data.EntityTypes)
+}
+
+// lineError returns the error at the line of the i-th item, if it is known.
+func lineError(lines []int, i int, err error) error {
+	if i >= len(lines) {
+		return err
+	}
+	return &yamlError{Line: lines[i], Msg: err.Error()}
+}
+
+// flattenIntents returns the intents followed by their followup intents.
+func flattenIntents(intents []Intent) []Intent {
+	var flattened []Intent
+	for _, intent := range intents {
+		flattened = append(flattened, intent)
+		flattened = append(flattened, flattenIntents(intent.FollowupIntents)...)
+	}
+	return flattened
+}
+
+func (l *lint) checkDisplayNames(intents []Intent, entityTypes []EntityType) {
+	seen := make(map[string]bool)
+	for _, intent := range intents {
+		if seen[intent.DisplayName] {
+			l.report(LintRuleDuplicateName, "intent %q is defined more than once", intent.DisplayName)
+		}
+		seen[intent.DisplayName] = true
+	}
+
+	seen = make(map[string]bool)
+	for _, entityType := range entityTypes {
+		if seen[entityType.DisplayName] {
+			l.report(LintRuleDuplicateName, "entity type %q is defined more than once", entityType.DisplayName)
+		}
+		seen[entityType.DisplayName] = true
+	}
+}
+
+// parameterReferenceRegexp matches a reference to a parameter in a response,
+// such as $name or $name.original.
+// Names start with a letter or underscore, so that prices such as $10 are not
+// references.
+var parameterReferenceRegexp = regexp.MustCompile(`\$([A-Za-z_][\w-]*)`)
+
+func (l *lint) checkIntents(intents []Intent) {
+	for _, intent := range intents {
+		if len(intent.TrainingPhrases) == 0 && len(intent.Events) == 0 && !intent.IsFallback {
+			l.report(LintRuleEmptyIntent, "intent %q has no training phrases or events", intent.DisplayName)
+		}
+
+		parameters := make(map[string]bool)
+		for _, p := range intent.Parameters {
+			parameters[p.DisplayName] = true
+		}
+
+		reported := make(map[string]bool)
+		for _, text := range intentTexts(intent) {
+			for _, match := range parameterReferenceRegexp.FindAllStringSubmatch(text, -1) {
+				name := match[1]
+				if parameters[name] || reported[name] {
+					continue
+				}
+				reported[name] = true
+				l.report(LintRuleUnknownParameter, "intent %q: response %q references unknown parameter $%s", intent.DisplayName, text, name)
+			}
+		}
+	}
+}
+
+// intentTexts returns the texts of the responses and parameter prompts of the
+// intent in all its languages.
+func intentTexts(intent Intent) []string {
+	var texts []string
+	for _, i := range append([]Intent{intent}, intent.Translations...) {
+		for _, m := range i.Messages {
+			texts = append(texts, m.Text...)
+			for _, r := range m.SimpleResponses {
+				texts = append(texts, r.TextToSpeech, r.SSML, r.DisplayText)
+			}
+		}
+		for _, p := range i.Parameters {
+			texts = append(texts, p.Prompts...)
+		}
+	}
+	return texts
+}
+
+func (l *lint) checkDuplicatePhrases(intents []Intent) {
+	type phraseKey struct {
+		languageCode string
+		phrase       string
+	}
+
+	var (
+		keys    []phraseKey
+		phrases = make(map[phraseKey][]string)
+	)
+	for _, intent := range intents {
+		for _, i := range append([]Intent{intent}, intent.Translations...) {
+			for _, t := range i.TrainingPhrases {
+				key := phraseKey{languageCode: i.LanguageCode, phrase: normalizePhrase(t)}
+				names := phrases[key]
+				if len(names) > 0 && names[len(names)-1] == intent.DisplayName {
+					continue
+				}
+				if len(names) == 0 {
+					keys = append(keys, key)
+				}
+				phrases[key] = append(names, intent.DisplayName)
+			}
+		}
+	}
+
+	for _, key := range keys {
+		if names := phrases[key]; len(names) > 1 {
+			l.report(LintRuleDuplicatePhrase, "training phrase %q is used by intents %s", key.phrase, quoteNames(names))
+		}
+	}
+}
+
+// normalizePhrase returns the text of the training phrase in lower case with
+// its spaces collapsed.
+func normalizePhrase(trainingPhrase TrainingPhrase) string {
+	var text string
+	for _, p := range trainingPhrase.Parts {
+		text += p.Text
+	}
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// useIntentEntityTypes calls use for every entity type of the annotations of
+// the intent in all its languages, and of its parameters without one, with a
+// description of where it is used.
+func useIntentEntityTypes(intent Intent, use func(entityType string, format string, args ...interface{})) {
+	reported := make(map[string]bool)
+	for _, i := range append([]Intent{intent}, intent.Translations...) {
+		for _, t := range i.TrainingPhrases {
+			for _, p := range t.Parts {
+				if p.EntityType != "" {
+					reported[strings.TrimPrefix(p.EntityType, "@")] = true
+					use(p.EntityType, "intent %q: training phrase %q", intent.DisplayName, formatTrainingPhrase(t.Parts))
+				}
+			}
+		}
+	}
+	for _, p := range intent.Parameters {
+		if p.EntityTypeDisplayName != "" && !reported[strings.TrimPrefix(p.EntityTypeDisplayName, "@")] {
+			use(p.EntityTypeDisplayName, "intent %q: parameter %q", intent.DisplayName, p.DisplayName)
+		}
+	}
+}
+
+// checkSystemEntityTypes reports the entity types of the intents that look
+// like system entity types, such as @sys.dat, but are not.
+func (l *lint) checkSystemEntityTypes(intents []Intent) {
+	for _, intent := range intents {
+		useIntentEntityTypes(intent, func(entityType string, format string, args ...interface{}) {
+			name := strings.TrimPrefix(entityType, "@")
+			if strings.HasPrefix(name, "sys.") && !isSystemEntityType(name) {
+				l.report(LintRuleUnknownEntityType, format+" references unknown entity type @%s", append(args, name)...)
+			}
+		})
+	}
+}
+
+func (l *lint) checkEntityTypeUsage(intents []Intent, entityTypes []EntityType) {
+	defined := make(map[string]bool)
+	for _, entityType := range entityTypes {
+		defined[entityType.DisplayName] = true
+	}
+
+	used := make(map[string]bool)
+	use := func(entityType string, format string, args ...interface{}) {
+		name := strings.TrimPrefix(entityType, "@")
+		used[name] = true
+		if !defined[name] && !isSystemEntityType(name) {
+			l.report(LintRuleUnknownEntityType, format+" references unknown entity type @%s", append(args, name)...)
+		}
+	}
+
+	for _, intent := range intents {
+		useIntentEntityTypes(intent, use)
+	}
+
+	for _, entityType := range entityTypes {
+		var references []string
+		for _, e := range append([]EntityType{entityType}, entityType.Translations...) {
+			references = append(references, compositeReferences(e)...)
+		}
+		for _, reference := range references {
+			use(reference, "entity type %q", entityType.DisplayName)
+		}
+	}
+
+	for _, entityType := range entityTypes {
+		if !used[entityType.DisplayName] {
+			l.report(LintRuleUnusedEntityType, "entity type %q is not used by any intent or entity type", entityType.DisplayName)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package dialogflow_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+)
+
+func TestLint(t *testing.T) {
+	intentsSource, removeIntents := newTestSource(t, `
+intents:
+  - name: Order pizza
+    usersays:
+      - A @size:large pizza with @topping:cheese
+      - I want a pizza
+    responses:
+      - A $size pizza for $name
+  - name: Order drink
+    usersays:
+      - I want a  Pizza
+    responses:
+      - That costs $10, or $5 off
+    followup:
+      - name: Order pizza
+        events:
+          - ORDER
+  - name: Nothing
+`)
+	defer removeIntents()
+
+	entityTypesSource, removeEntityTypes := newTestSource(t, `
+entities:
+  - type: size
+    values:
+      - small
+      - large
+  - type: crust
+    values:
+      - thin
+  - type: order
+    values:
+      - "@size:size @drink:drink"
+`)
+	defer removeEntityTypes()
+
+	problems, err := dialogflow.NewLinter(intentsSource, entityTypesSource, dialogflow.DefaultLimits).Lint(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+
+	expected := []string{
+		`duplicate-name: intent "Order pizza" is defined more than once`,
+		`unknown-parameter: intent "Order pizza": response "A $size pizza for $name" references unknown parameter $name`,
+		`empty-intent: intent "Nothing" has no training phrases or events`,
+		`duplicate-phrase: training phrase "i want a pizza" is used by intents "Order pizza", "Order drink"`,
+		`unknown-entity-type: intent "Order pizza": training phrase "A @size:'large' pizza with @topping:'cheese'" references unknown entity type @topping`,
+		`unknown-entity-type: entity type "order" references unknown entity type @drink`,
+		`unused-entity-type: entity type "crust" is not used by any intent or entity type`,
+		`unused-entity-type: entity type "order" is not used by any intent or entity type`,
+	}
+
+	if !reflect.DeepEqual(expected, messages) {
+		t.Errorf("expected %q, got %q", expected, messages)
+	}
+}
+
+func TestLintInvalid(t *testing.T) {
+	intentsSource, removeIntents := newTestSource(t, `
+intents:
+  - name: Order pizza
+    usersays:
+      - A @size:'large pizza
+  - name: Order drink
+    usersays:
+      - A drink at @sys.tme:noon
+  - name: Order
+    events:
+      - ""
+`)
+	defer removeIntents()
+
+	entityTypesSource := dialogflow.NewFileSource("missing.yaml")
+
+	problems, err := dialogflow.NewLinter(intentsSource, entityTypesSource, dialogflow.DefaultLimits).Lint(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, strings.Replace(problem.String(), intentsSource.Name(), "intents.yaml", 1))
+	}
+
+	expected := []string{
+		`invalid: missing.yaml: open file: open missing.yaml: no such file or directory`,
+		`invalid: intents.yaml:3: intent "Order pizza": training phrase "A @size:'large pizza": column 9: unterminated quote`,
+		`invalid: intents.yaml:9: intent "Order": event name is empty`,
+		`unknown-entity-type: intent "Order drink": training phrase "A drink at @sys.tme:'noon'" references unknown entity type @sys.tme`,
+	}
+
+	if !reflect.DeepEqual(expected, messages) {
+		t.Errorf("expected %q, got %q", expected, messages)
+	}
+}
+
+func TestLintWithoutEntities(t *testing.T) {
+	intentsSource, removeIntents := newTestSource(t, `
+intents:
+  - name: Order pizza
+    usersays:
+      - A @size:large pizza @sys.dat:today
+    parameters:
+      - name: time
+        entity: "@sys.time"
+`)
+	defer removeIntents()
+
+	problems, err := dialogflow.NewLinter(intentsSource, nil, dialogflow.DefaultLimits).Lint(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+
+	expected := []string{
+		`unknown-entity-type: intent "Order pizza": training phrase "A @size:'large' pizza @sys.dat:'today'" references unknown entity type @sys.dat`,
+	}
+
+	if !reflect.DeepEqual(expected, messages) {
+		t.Errorf("expected %q, got %q", expected, messages)
+	}
+}
@@ -0,0 +1,88 @@
+package dialogflow
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/dialogflow/apiv2"
+)
+
+// DefaultPollInterval is the interval at which WaitOperation is usually
+// called to poll an operation.
+const DefaultPollInterval = 2 * time.Second
+
+// Operation is a long-running operation started by a batch method.
+type Operation interface {
+	// Name returns the name of the operation.
+	Name() string
+	// Done reports whether the operation has finished, as of the last poll.
+	Done() bool
+	// Poll fetches the latest state of the operation. Once the operation is
+	// done, Poll returns the error the server reported for it, if any.
+	Poll(ctx context.Context) error
+}
+
+// WaitOperation polls the operation every pollInterval until it is done or
+// until ctx is done, and returns the error of the operation. If progress is
+// not nil, it is called after every poll.
+func WaitOperation(ctx context.Context, op Operation, pollInterval time.Duration, progress func(Operation)) error {
+	for {
+		if err := op.Poll(ctx); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(op)
+		}
+		if op.Done() {
+			return nil
+		}
+
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+type batchDeleteIntentsOperation struct {
+	*dialogflow.BatchDeleteIntentsOperation
+}
+
+func (op batchDeleteIntentsOperation) Poll(ctx context.Context) error {
+	return op.BatchDeleteIntentsOperation.Poll(ctx)
+}
+
+type batchDeleteEntityTypesOperation struct {
+	*dialogflow.BatchDeleteEntityTypesOperation
+}
+
+func (op batchDeleteEntityTypesOperation) Poll(ctx context.Context) error {
+	return op.BatchDeleteEntityTypesOperation.Poll(ctx)
+}
+
+type batchCreateEntitiesOperation struct {
+	*dialogflow.BatchCreateEntitiesOperation
+}
+
+func (op batchCreateEntitiesOperation) Poll(ctx context.Context) error {
+	return op.BatchCreateEntitiesOperation.Poll(ctx)
+}
+
+type batchUpdateEntitiesOperation struct {
+	*dialogflow.BatchUpdateEntitiesOperation
+}
+
+func (op batchUpdateEntitiesOperation) Poll(ctx context.Context) error {
+	return op.BatchUpdateEntitiesOperation.Poll(ctx)
+}
+
+type batchDeleteEntitiesOperation struct {
+	*dialogflow.BatchDeleteEntitiesOperation
+}
+
+func (op batchDeleteEntitiesOperation) Poll(ctx context.Context) error {
+	return op.BatchDeleteEntitiesOperation.Poll(ctx)
+}
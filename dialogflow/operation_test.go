@@ -0,0 +1,123 @@
+package dialogflow_test
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow/dialogflowtest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWaitOperation(t *testing.T) {
+	op := &dialogflowtest.Operation{OperationName: "operations/1", Polls: 3}
+
+	var polls int
+	err := dialogflow.WaitOperation(context.Background(), op, time.Millisecond, func(dialogflow.Operation) {
+		polls++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 3 {
+		t.Errorf("expected 3 polls, got %d", polls)
+	}
+
+	op = &dialogflowtest.Operation{OperationName: "operations/2", Polls: 1, Err: errors.New("failed")}
+	if err = dialogflow.WaitOperation(context.Background(), op, time.Millisecond, nil); err == nil || err.Error() != "failed" {
+		t.Errorf("expected error failed, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	op = &dialogflowtest.Operation{OperationName: "operations/3", Polls: 1000}
+	if err = dialogflow.WaitOperation(ctx, op, time.Millisecond, nil); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestDeleteIntentsOperation(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	intent, err := intentsClient.CreateIntent(context.Background(), dialogflow.Intent{DisplayName: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.SetOperationResult(2, status.Error(codes.Internal, "deletion failed"))
+
+	op, err := intentsClient.DeleteIntents(context.Background(), []dialogflow.Intent{intent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Done() {
+		t.Error("expected operation to be pending")
+	}
+
+	var polls int
+	err = dialogflow.WaitOperation(context.Background(), op, time.Millisecond, func(dialogflow.Operation) {
+		polls++
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected internal error, got %v", err)
+	}
+	if polls != 1 {
+		t.Errorf("expected progress after the pending poll only, got %d polls", polls)
+	}
+}
+
+func TestBatchCreateEntitiesOperation(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	entityTypesClient := newTestEntityTypesClient(t, server)
+	defer entityTypesClient.Close()
+
+	entityType, err := entityTypesClient.CreateEntityType(context.Background(), dialogflow.EntityType{
+		DisplayName: "size",
+		Kind:        "KIND_MAP",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.SetOperationResult(2, nil)
+
+	op, err := entityTypesClient.BatchCreateEntities(context.Background(), path.Base(entityType.Name), "", []dialogflow.Entity{
+		{Value: "small", Synonyms: []string{"small"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Done() {
+		t.Error("expected operation to be pending")
+	}
+
+	var polls int
+	err = dialogflow.WaitOperation(context.Background(), op, time.Millisecond, func(dialogflow.Operation) {
+		polls++
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if polls != 2 {
+		t.Errorf("expected 2 polls, got %d", polls)
+	}
+
+	entityType, err = entityTypesClient.GetEntityType(context.Background(), path.Base(entityType.Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entityType.Entities) != 1 || entityType.Entities[0].Value != "small" {
+		t.Errorf("expected entity small, got %v", entityType.Entities)
+	}
+}
@@ -0,0 +1,516 @@
+package dialogflow
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+)
+
+type ChangeAction string
+
+const (
+	ChangeActionAdd    ChangeAction = "add"
+	ChangeActionChange ChangeAction = "change"
+	ChangeActionRemove ChangeAction = "remove"
+)
+
+type Plan struct {
+	EntityTypes []EntityTypeChange `json:"entityTypes"`
+	Intents     []IntentChange     `json:"intents"`
+}
+
+type IntentChange struct {
+	Action          ChangeAction  `json:"action"`
+	DisplayName     string        `json:"displayName"`
+	LanguageCode    string        `json:"languageCode,omitempty"`
+	Fields          []FieldChange `json:"fields,omitempty"`
+	TrainingPhrases []ValueChange `json:"trainingPhrases,omitempty"`
+	Responses       []ValueChange `json:"responses,omitempty"`
+	Contexts        []ValueChange `json:"contexts,omitempty"`
+	Parameters      []ValueChange `json:"parameters,omitempty"`
+
+	intent            Intent
+	remoteIntent      Intent
+	parentDisplayName string
+}
+
+type EntityTypeChange struct {
+	Action       ChangeAction   `json:"action"`
+	DisplayName  string         `json:"displayName"`
+	LanguageCode string         `json:"languageCode,omitempty"`
+	Fields       []FieldChange  `json:"fields,omitempty"`
+	Entities     []EntityChange `json:"entities,omitempty"`
+
+	entityType       EntityType
+	remoteEntityType EntityType
+}
+
+type EntityChange struct {
+	Action   ChangeAction  `json:"action"`
+	Value    string        `json:"value"`
+	Synonyms []ValueChange `json:"synonyms,omitempty"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type ValueChange struct {
+	Action ChangeAction `json:"action"`
+	Value  string       `json:"value"`
+	Old    string       `json:"old,omitempty"`
+}
+
+// planIntents compares the local intents with the remote intents by display
+// name, and their translations with the remote intents in the languages of
+// the translations. Added and changed intents are returned in the order they
+// have to be applied, parents before their followup intents, followed by the
+// changed translations and the removed intents.
+func planIntents(intents, remoteIntents []Intent, remoteTranslations map[string][]Intent) []IntentChange {
+	remote := make(map[string]Intent)
+	displayNames := make(map[string]string)
+	for _, intent := range remoteIntents {
+		remote[intent.DisplayName] = intent
+		displayNames[intent.Name] = intent.DisplayName
+	}
+
+	remoteTranslated := make(map[string]map[string]Intent)
+	for languageCode, intents := range remoteTranslations {
+		remoteTranslated[languageCode] = make(map[string]Intent)
+		for _, intent := range intents {
+			remoteTranslated[languageCode][intent.DisplayName] = intent
+		}
+	}
+
+	local := make(map[string]bool)
+	var changes, translationChanges []IntentChange
+
+	var walk func(intents []Intent, parentDisplayName string)
+	walk = func(intents []Intent, parentDisplayName string) {
+		for _, intent := range intents {
+			local[intent.DisplayName] = true
+			remoteIntent, ok := remote[intent.DisplayName]
+			remoteParentDisplayName := displayNames[remoteIntent.ParentFollowupIntentName]
+
+			change := diffIntent(intent, parentDisplayName, remoteIntent, remoteParentDisplayName)
+			change.remoteIntent = remoteIntent
+			change.parentDisplayName = parentDisplayName
+			if !ok {
+				change.Action = ChangeActionAdd
+				changes = append(changes, change)
+			} else if change.changed() {
+				change.Action = ChangeActionChange
+				changes = append(changes, change)
+			}
+
+			for _, translation := range intent.Translations {
+				remoteTranslation, remoteOk := remoteTranslated[translation.LanguageCode][intent.DisplayName]
+				change := diffIntentTranslation(intent, translation, remoteTranslation)
+				change.parentDisplayName = parentDisplayName
+				if !ok || !remoteOk {
+					change.Action = ChangeActionAdd
+					translationChanges = append(translationChanges, change)
+				} else if change.changed() {
+					change.Action = ChangeActionChange
+					translationChanges = append(translationChanges, change)
+				}
+			}
+
+			walk(intent.FollowupIntents, intent.DisplayName)
+		}
+	}
+	walk(intents, "")
+	changes = append(changes, translationChanges...)
+
+	for _, intent := range remoteIntents {
+		if local[intent.DisplayName] {
+			continue
+		}
+		change := diffIntent(Intent{}, "", intent, "")
+		change.Action = ChangeActionRemove
+		change.DisplayName = intent.DisplayName
+		change.Fields = nil
+		change.remoteIntent = intent
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func diffIntent(intent Intent, parentDisplayName string, remoteIntent Intent, remoteParentDisplayName string) IntentChange {
+	change := IntentChange{
+		DisplayName: intent.DisplayName,
+		intent:      intent,
+	}
+
+	if intent.IsFallback != remoteIntent.IsFallback {
+		change.Fields = append(change.Fields, FieldChange{
+			Field: "fallback",
+			Old:   strconv.FormatBool(remoteIntent.IsFallback),
+			New:   strconv.FormatBool(intent.IsFallback),
+		})
+	}
+	for _, field := range []FieldChange{
+		{Field: "action", Old: remoteIntent.Action, New: intent.Action},
+		{
+			Field: "priority",
+			Old:   strconv.Itoa(int(intentPriority(remoteIntent.Priority))),
+			New:   strconv.Itoa(int(intentPriority(intent.Priority))),
+		},
+		{Field: "events", Old: strings.Join(remoteIntent.Events, ", "), New: strings.Join(intent.Events, ", ")},
+		{
+			Field: "webhook",
+			Old:   toDialogflowWebhookState(remoteIntent.WebhookState).String(),
+			New:   toDialogflowWebhookState(intent.WebhookState).String(),
+		},
+		{Field: "mlDisabled", Old: strconv.FormatBool(remoteIntent.MlDisabled), New: strconv.FormatBool(intent.MlDisabled)},
+		{Field: "resetContexts", Old: strconv.FormatBool(remoteIntent.ResetContexts), New: strconv.FormatBool(intent.ResetContexts)},
+	} {
+		if field.Old != field.New {
+			change.Fields = append(change.Fields, field)
+		}
+	}
+	if parentDisplayName != remoteParentDisplayName {
+		change.Fields = append(change.Fields, FieldChange{
+			Field: "parent",
+			Old:   remoteParentDisplayName,
+			New:   parentDisplayName,
+		})
+	}
+
+	change.TrainingPhrases = diffValues(
+		formatTrainingPhrases(intent.TrainingPhrases),
+		formatTrainingPhrases(remoteIntent.TrainingPhrases),
+	)
+	change.Responses = diffValues(formatMessages(intent.Messages), formatMessages(remoteIntent.Messages))
+	change.Contexts = diffValues(formatContexts(intent), formatContexts(remoteIntent))
+	change.Parameters = diffKeyedValues(parameterValues(intent.Parameters), parameterValues(remoteIntent.Parameters))
+
+	return change
+}
+
+// diffIntentTranslation compares the training phrases, messages and parameter
+// prompts of the translation with the remote intent in its language.
+func diffIntentTranslation(intent Intent, translation Intent, remoteTranslation Intent) IntentChange {
+	return IntentChange{
+		DisplayName:  intent.DisplayName,
+		LanguageCode: translation.LanguageCode,
+		TrainingPhrases: diffValues(
+			formatTrainingPhrases(translation.TrainingPhrases),
+			formatTrainingPhrases(remoteTranslation.TrainingPhrases),
+		),
+		Responses:  diffValues(formatMessages(translation.Messages), formatMessages(remoteTranslation.Messages)),
+		Parameters: diffKeyedValues(parameterPrompts(translation.Parameters), parameterPrompts(remoteTranslation.Parameters)),
+		intent:     translateIntent(intent, translation),
+	}
+}
+
+// intentPriority returns the priority Dialogflow gives to an intent with the
+// given priority.
+func intentPriority(priority int32) int32 {
+	if priority == 0 {
+		return DefaultIntentPriority
+	}
+	return priority
+}
+
+func (change IntentChange) changed() bool {
+	return len(change.Fields) > 0 ||
+		len(change.TrainingPhrases) > 0 ||
+		len(change.Responses) > 0 ||
+		len(change.Contexts) > 0 ||
+		len(change.Parameters) > 0
+}
+
+// planEntityTypes compares the local entity types with the remote entity
+// types by display name, and their translations with the remote entity types
+// in the languages of the translations. The changed translations follow the
+// added and changed entity types.
+func planEntityTypes(entityTypes, remoteEntityTypes []EntityType, remoteTranslations map[string][]EntityType) []EntityTypeChange {
+	remote := make(map[string]EntityType)
+	for _, entityType := range remoteEntityTypes {
+		remote[entityType.DisplayName] = entityType
+	}
+
+	remoteTranslated := make(map[string]map[string]EntityType)
+	for languageCode, entityTypes := range remoteTranslations {
+		remoteTranslated[languageCode] = make(map[string]EntityType)
+		for _, entityType := range entityTypes {
+			remoteTranslated[languageCode][entityType.DisplayName] = entityType
+		}
+	}
+
+	local := make(map[string]bool)
+	var changes, translationChanges []EntityTypeChange
+
+	for _, entityType := range entityTypes {
+		local[entityType.DisplayName] = true
+		remoteEntityType, ok := remote[entityType.DisplayName]
+
+		for _, translation := range entityType.Translations {
+			remoteTranslation, remoteOk := remoteTranslated[translation.LanguageCode][entityType.DisplayName]
+			change := diffEntityType(translation, remoteTranslation)
+			change.LanguageCode = translation.LanguageCode
+			change.remoteEntityType = remoteEntityType
+			if !ok || !remoteOk {
+				change.Action = ChangeActionAdd
+				translationChanges = append(translationChanges, change)
+			} else if change.changed() {
+				change.Action = ChangeActionChange
+				translationChanges = append(translationChanges, change)
+			}
+		}
+
+		change := diffEntityType(entityType, remoteEntityType)
+		change.remoteEntityType = remoteEntityType
+		if !ok {
+			change.Action = ChangeActionAdd
+			changes = append(changes, change)
+			continue
+		}
+		change.Fields = diffEntityTypeFields(entityType, remoteEntityType)
+		if change.changed() {
+			change.Action = ChangeActionChange
+			changes = append(changes, change)
+		}
+	}
+	changes = append(changes, translationChanges...)
+
+	for _, entityType := range remoteEntityTypes {
+		if local[entityType.DisplayName] {
+			continue
+		}
+		change := diffEntityType(EntityType{}, entityType)
+		change.Action = ChangeActionRemove
+		change.DisplayName = entityType.DisplayName
+		change.remoteEntityType = entityType
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func diffEntityType(entityType, remoteEntityType EntityType) EntityTypeChange {
+	change := EntityTypeChange{
+		DisplayName: entityType.DisplayName,
+		entityType:  entityType,
+	}
+
+	synonyms := entitySynonyms(entityType.Entities)
+	remoteSynonyms := entitySynonyms(remoteEntityType.Entities)
+
+	for _, value := range mergeKeys(stringSliceMapKeys(synonyms), stringSliceMapKeys(remoteSynonyms)) {
+		localValues, ok := synonyms[value]
+		remoteValues, remoteOk := remoteSynonyms[value]
+		entityChange := EntityChange{
+			Value:    value,
+			Synonyms: diffValues(localValues, remoteValues),
+		}
+		switch {
+		case !remoteOk:
+			entityChange.Action = ChangeActionAdd
+		case !ok:
+			entityChange.Action = ChangeActionRemove
+		case len(entityChange.Synonyms) > 0:
+			entityChange.Action = ChangeActionChange
+		default:
+			continue
+		}
+		change.Entities = append(change.Entities, entityChange)
+	}
+
+	return change
+}
+
+func diffEntityTypeFields(entityType, remoteEntityType EntityType) []FieldChange {
+	// An entity type without a kind or auto expansion mode is created with
+	// the defaults of CreateEntityType.
+	kind := entityType.Kind
+	if _, ok := dialogflowpb.EntityType_Kind_value[kind]; !ok {
+		kind = dialogflowpb.EntityType_KIND_LIST.String()
+	}
+	autoExpansionMode := entityType.AutoExpansionMode
+	if _, ok := dialogflowpb.EntityType_AutoExpansionMode_value[autoExpansionMode]; !ok {
+		autoExpansionMode = dialogflowpb.EntityType_AUTO_EXPANSION_MODE_UNSPECIFIED.String()
+	}
+
+	var fields []FieldChange
+	if kind != remoteEntityType.Kind {
+		fields = append(fields, FieldChange{
+			Field: "kind",
+			Old:   remoteEntityType.Kind,
+			New:   kind,
+		})
+	}
+	if autoExpansionMode != remoteEntityType.AutoExpansionMode {
+		fields = append(fields, FieldChange{
+			Field: "autoExpansionMode",
+			Old:   remoteEntityType.AutoExpansionMode,
+			New:   autoExpansionMode,
+		})
+	}
+	if entityType.EnableFuzzyExtraction != remoteEntityType.EnableFuzzyExtraction {
+		fields = append(fields, FieldChange{
+			Field: "fuzzy",
+			Old:   strconv.FormatBool(remoteEntityType.EnableFuzzyExtraction),
+			New:   strconv.FormatBool(entityType.EnableFuzzyExtraction),
+		})
+	}
+	return fields
+}
+
+func (change EntityTypeChange) changed() bool {
+	return len(change.Fields) > 0 || len(change.Entities) > 0
+}
+
+// entitySynonyms maps the entity values to their synonyms. An entity without
+// synonyms is a synonym of itself.
+func entitySynonyms(entities []Entity) map[string][]string {
+	synonyms := make(map[string][]string)
+	for _, entity := range entities {
+		values := entity.Synonyms
+		if len(values) == 0 {
+			values = []string{entity.Value}
+		}
+		synonyms[entity.Value] = values
+	}
+	return synonyms
+}
+
+// diffValues returns the values that were added to or removed from the
+// remote values, ignoring their order.
+func diffValues(values, remoteValues []string) []ValueChange {
+	counts := make(map[string]int)
+	for _, value := range remoteValues {
+		counts[value]++
+	}
+
+	var changes []ValueChange
+	for _, value := range values {
+		if counts[value] > 0 {
+			counts[value]--
+			continue
+		}
+		changes = append(changes, ValueChange{Action: ChangeActionAdd, Value: value})
+	}
+	for _, value := range remoteValues {
+		if counts[value] > 0 {
+			counts[value]--
+			changes = append(changes, ValueChange{Action: ChangeActionRemove, Value: value})
+		}
+	}
+	return changes
+}
+
+// diffKeyedValues returns the values that were added, changed or removed,
+// matching the values by key.
+func diffKeyedValues(values, remoteValues map[string]string) []ValueChange {
+	var changes []ValueChange
+	for _, key := range mergeKeys(stringMapKeys(values), stringMapKeys(remoteValues)) {
+		value, ok := values[key]
+		remoteValue, remoteOk := remoteValues[key]
+		switch {
+		case !remoteOk:
+			changes = append(changes, ValueChange{Action: ChangeActionAdd, Value: value})
+		case !ok:
+			changes = append(changes, ValueChange{Action: ChangeActionRemove, Value: remoteValue})
+		case value != remoteValue:
+			changes = append(changes, ValueChange{Action: ChangeActionChange, Value: value, Old: remoteValue})
+		}
+	}
+	return changes
+}
+
+func mergeKeys(keys ...[]string) []string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, k := range keys {
+		for _, key := range k {
+			if !seen[key] {
+				seen[key] = true
+				merged = append(merged, key)
+			}
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+func stringMapKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func stringSliceMapKeys(m map[string][]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func formatTrainingPhrases(trainingPhrases []TrainingPhrase) []string {
+	var values []string
+	for _, t := range trainingPhrases {
+		values = append(values, formatTrainingPhrase(t.Parts))
+	}
+	return values
+}
+
+// formatContexts returns the input and output contexts of the intent by their
+// short names, such as "in: myname-followup" and "out: mood (5)".
+func formatContexts(intent Intent) []string {
+	var values []string
+	for _, name := range intent.InputContextNames {
+		values = append(values, fmt.Sprintf("in: %s", shortContextName(name)))
+	}
+	for _, c := range intent.OutputContexts {
+		values = append(values, fmt.Sprintf("out: %s (%d)", shortContextName(c.Name), c.LifespanCount))
+	}
+	return values
+}
+
+func parameterValues(parameters []Parameter) map[string]string {
+	values := make(map[string]string)
+	for _, p := range parameters {
+		value := fmt.Sprintf("%s: %s = %s", p.DisplayName, p.EntityTypeDisplayName, p.Value)
+		var flags []string
+		if p.Mandatory {
+			flags = append(flags, "mandatory")
+		}
+		if p.IsList {
+			flags = append(flags, "list")
+		}
+		if p.DefaultValue != "" {
+			flags = append(flags, fmt.Sprintf("default %s", p.DefaultValue))
+		}
+		if len(p.Prompts) > 0 {
+			flags = append(flags, fmt.Sprintf("prompts %q", p.Prompts))
+		}
+		if len(flags) > 0 {
+			value += fmt.Sprintf(" (%s)", strings.Join(flags, ", "))
+		}
+		values[p.DisplayName] = value
+	}
+	return values
+}
+
+// parameterPrompts returns the prompts of the parameters that have prompts,
+// the only part of the parameters that is translated.
+func parameterPrompts(parameters []Parameter) map[string]string {
+	values := make(map[string]string)
+	for _, p := range parameters {
+		if len(p.Prompts) > 0 {
+			values[p.DisplayName] = fmt.Sprintf("%s: prompts %q", p.DisplayName, p.Prompts)
+		}
+	}
+	return values
+}
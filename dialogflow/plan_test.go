@@ -0,0 +1,289 @@
+package dialogflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanIntents(t *testing.T) {
+	intents, err := readIntents([]byte(`
+intents:
+  - name: My name is @name
+    usersays:
+      - Hi, my name is @name:John
+      - I am @name:John
+    responses:
+      - Hi $name
+    followup:
+      - name: I am good
+        usersays:
+          - I am good
+        responses:
+          - Great
+  - name: How are you?
+    usersays:
+      - How are you?
+    responses:
+      - I'm great, thanks.
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	myName := intents[0]
+	myName.Name = "projects/example/agent/intents/1"
+	myName.TrainingPhrases = []TrainingPhrase{intents[0].TrainingPhrases[1], intents[0].TrainingPhrases[0]}
+	myName.FollowupIntents = nil
+
+	iAmGood := intents[0].FollowupIntents[0]
+	iAmGood.Name = "projects/example/agent/intents/2"
+	iAmGood.ParentFollowupIntentName = myName.Name
+	iAmGood.Messages = []Message{
+		{Text: []string{"Good"}},
+		{QuickReplies: &QuickReplies{QuickReplies: []string{"Thanks"}}, Platform: "TELEGRAM"},
+	}
+
+	removed := Intent{
+		Name:            "projects/example/agent/intents/3",
+		DisplayName:     "Goodbye",
+		TrainingPhrases: []TrainingPhrase{{Parts: []TrainingPhrasePart{{Text: "Bye"}}}},
+	}
+
+	changes := planIntents(intents, []Intent{myName, iAmGood, removed}, nil)
+
+	expected := []IntentChange{
+		{
+			Action:      ChangeActionChange,
+			DisplayName: "I am good",
+			Responses: []ValueChange{
+				{Action: ChangeActionAdd, Value: "Great"},
+				{Action: ChangeActionRemove, Value: "Good"},
+				{Action: ChangeActionRemove, Value: `telegram: {"quickReplies":{"replies":["Thanks"]}}`},
+			},
+		},
+		{
+			Action:      ChangeActionAdd,
+			DisplayName: "How are you?",
+			TrainingPhrases: []ValueChange{
+				{Action: ChangeActionAdd, Value: "How are you?"},
+			},
+			Responses: []ValueChange{
+				{Action: ChangeActionAdd, Value: "I'm great, thanks."},
+			},
+		},
+		{
+			Action:      ChangeActionRemove,
+			DisplayName: "Goodbye",
+			TrainingPhrases: []ValueChange{
+				{Action: ChangeActionRemove, Value: "Bye"},
+			},
+		},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d", len(expected), len(changes))
+	}
+
+	if changes[0].parentDisplayName != "My name is @name" {
+		t.Errorf("expected parent display name %q, got %q", "My name is @name", changes[0].parentDisplayName)
+	}
+
+	for i := range expected {
+		change := changes[i]
+		change.intent, change.remoteIntent, change.parentDisplayName = Intent{}, Intent{}, ""
+		if !reflect.DeepEqual(expected[i], change) {
+			t.Errorf("expected %+v, got %+v", expected[i], change)
+		}
+	}
+}
+
+func TestPlanEntityTypes(t *testing.T) {
+	entityTypes := []EntityType{
+		{
+			DisplayName: "colour",
+			Entities:    []Entity{{Value: "red"}, {Value: "blue"}},
+		},
+	}
+	remoteEntityTypes := []EntityType{
+		{
+			Name:              "projects/example/agent/entityTypes/1",
+			DisplayName:       "colour",
+			Kind:              "KIND_LIST",
+			AutoExpansionMode: "AUTO_EXPANSION_MODE_UNSPECIFIED",
+			Entities: []Entity{
+				{Value: "blue", Synonyms: []string{"blue"}},
+				{Value: "red", Synonyms: []string{"red"}},
+			},
+		},
+	}
+
+	if changes := planEntityTypes(entityTypes, remoteEntityTypes, nil); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+
+	remoteEntityTypes[0].Entities = []Entity{
+		{Value: "red", Synonyms: []string{"red", "crimson"}},
+	}
+
+	changes := planEntityTypes(entityTypes, remoteEntityTypes, nil)
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %d", len(changes))
+	}
+
+	expected := []EntityChange{
+		{Action: ChangeActionAdd, Value: "blue", Synonyms: []ValueChange{{Action: ChangeActionAdd, Value: "blue"}}},
+		{Action: ChangeActionChange, Value: "red", Synonyms: []ValueChange{{Action: ChangeActionRemove, Value: "crimson"}}},
+	}
+	if !reflect.DeepEqual(expected, changes[0].Entities) {
+		t.Errorf("expected %+v, got %+v", expected, changes[0].Entities)
+	}
+}
+
+func TestPlanIntentsEqual(t *testing.T) {
+	intents, err := readIntents([]byte(`
+intents:
+  - name: My name is @name
+    usersays:
+      - Hi, my name is @name:John
+      - I am @name:John
+    responses:
+      - Hi $name
+    parameters:
+      - name: age
+        entity: sys.number
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := intents[0]
+
+	tests := []struct {
+		name    string
+		remote  func(intent *Intent)
+		changed bool
+	}{
+		{name: "same", remote: func(*Intent) {}},
+		{
+			name: "reordered training phrases",
+			remote: func(intent *Intent) {
+				intent.TrainingPhrases = []TrainingPhrase{local.TrainingPhrases[1], local.TrainingPhrases[0]}
+			},
+		},
+		{
+			name: "reordered parameters",
+			remote: func(intent *Intent) {
+				intent.Parameters = []Parameter{local.Parameters[1], local.Parameters[0]}
+			},
+		},
+		{
+			name:   "default priority",
+			remote: func(intent *Intent) { intent.Priority = DefaultIntentPriority },
+		},
+		{
+			name:    "different responses",
+			remote:  func(intent *Intent) { intent.Messages = []Message{{Text: []string{"Hello $name"}}} },
+			changed: true,
+		},
+		{
+			name:    "missing training phrase",
+			remote:  func(intent *Intent) { intent.TrainingPhrases = local.TrainingPhrases[:1] },
+			changed: true,
+		},
+		{
+			name: "different parameter",
+			remote: func(intent *Intent) {
+				intent.Parameters = append([]Parameter(nil), local.Parameters...)
+				intent.Parameters[1].Mandatory = true
+			},
+			changed: true,
+		},
+		{
+			name:    "fallback",
+			remote:  func(intent *Intent) { intent.IsFallback = true },
+			changed: true,
+		},
+		{
+			name:    "followup intent",
+			remote:  func(intent *Intent) { intent.ParentFollowupIntentName = "projects/example/agent/intents/2" },
+			changed: true,
+		},
+	}
+
+	for _, test := range tests {
+		remote := local
+		remote.Name = "projects/example/agent/intents/1"
+		test.remote(&remote)
+
+		parent := Intent{Name: "projects/example/agent/intents/2", DisplayName: "Hello"}
+		changes := planIntents([]Intent{local, parent}, []Intent{remote, parent}, nil)
+		if changed := len(changes) > 0; changed != test.changed {
+			t.Errorf("%s: expected changed %t, got %+v", test.name, test.changed, changes)
+		}
+	}
+}
+
+func TestPlanEntityTypesEqual(t *testing.T) {
+	local := EntityType{
+		DisplayName: "colour",
+		Entities:    []Entity{{Value: "red", Synonyms: []string{"red", "crimson"}}, {Value: "blue"}},
+	}
+
+	tests := []struct {
+		name    string
+		remote  func(entityType *EntityType)
+		changed bool
+	}{
+		{name: "same", remote: func(*EntityType) {}},
+		{
+			name: "reordered entities and synonyms",
+			remote: func(entityType *EntityType) {
+				entityType.Entities = []Entity{
+					{Value: "blue", Synonyms: []string{"blue"}},
+					{Value: "red", Synonyms: []string{"crimson", "red"}},
+				}
+			},
+		},
+		{
+			name:    "missing entity",
+			remote:  func(entityType *EntityType) { entityType.Entities = entityType.Entities[:1] },
+			changed: true,
+		},
+		{
+			name: "missing synonym",
+			remote: func(entityType *EntityType) {
+				entityType.Entities = []Entity{{Value: "red", Synonyms: []string{"red"}}, {Value: "blue"}}
+			},
+			changed: true,
+		},
+		{
+			name:    "kind",
+			remote:  func(entityType *EntityType) { entityType.Kind = "KIND_MAP" },
+			changed: true,
+		},
+		{
+			name:    "auto expansion mode",
+			remote:  func(entityType *EntityType) { entityType.AutoExpansionMode = "AUTO_EXPANSION_MODE_DEFAULT" },
+			changed: true,
+		},
+		{
+			name:    "fuzzy extraction",
+			remote:  func(entityType *EntityType) { entityType.EnableFuzzyExtraction = true },
+			changed: true,
+		},
+	}
+
+	for _, test := range tests {
+		// The remote entity type has the defaults the local one is created
+		// with.
+		remote := local
+		remote.Name = "projects/example/agent/entityTypes/1"
+		remote.Kind = "KIND_LIST"
+		remote.AutoExpansionMode = "AUTO_EXPANSION_MODE_UNSPECIFIED"
+		test.remote(&remote)
+
+		changes := planEntityTypes([]EntityType{local}, []EntityType{remote}, nil)
+		if changed := len(changes) > 0; changed != test.changed {
+			t.Errorf("%s: expected changed %t, got %+v", test.name, test.changed, changes)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package dialogflow
+
+import (
+	"fmt"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/genproto/protobuf/field_mask"
+)
+
+// toFieldMask returns a field mask for the given proto field paths, such as
+// "training_phrases", or nil to update all fields.
+func toFieldMask(paths []string) *field_mask.FieldMask {
+	if len(paths) == 0 {
+		return nil
+	}
+	return &field_mask.FieldMask{Paths: paths}
+}
+
+func structToMap(s *structpb.Struct) map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+	m := make(map[string]interface{}, len(s.Fields))
+	for key, value := range s.Fields {
+		m[key] = valueToInterface(value)
+	}
+	return m
+}
+
+func valueToInterface(value *structpb.Value) interface{} {
+	switch kind := value.GetKind().(type) {
+	case *structpb.Value_BoolValue:
+		return kind.BoolValue
+	case *structpb.Value_NumberValue:
+		return kind.NumberValue
+	case *structpb.Value_StringValue:
+		return kind.StringValue
+	case *structpb.Value_ListValue:
+		values := make([]interface{}, len(kind.ListValue.GetValues()))
+		for i, v := range kind.ListValue.GetValues() {
+			values[i] = valueToInterface(v)
+		}
+		return values
+	case *structpb.Value_StructValue:
+		return structToMap(kind.StructValue)
+	default:
+		return nil
+	}
+}
+
+func mapToStruct(m map[string]interface{}) *structpb.Struct {
+	if m == nil {
+		return nil
+	}
+	s := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(m))}
+	for key, value := range m {
+		s.Fields[key] = interfaceToValue(value)
+	}
+	return s
+}
+
+// interfaceToValue converts the value to a protobuf value. Numbers are
+// converted to float64 and unsupported types to their string representation.
+func interfaceToValue(value interface{}) *structpb.Value {
+	switch v := value.(type) {
+	case nil:
+		return &structpb.Value{Kind: &structpb.Value_NullValue{}}
+	case bool:
+		return &structpb.Value{Kind: &structpb.Value_BoolValue{BoolValue: v}}
+	case string:
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: v}}
+	case float64:
+		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: v}}
+	case float32:
+		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(v)}}
+	case int:
+		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(v)}}
+	case int32:
+		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(v)}}
+	case int64:
+		return &structpb.Value{Kind: &structpb.Value_NumberValue{NumberValue: float64(v)}}
+	case []interface{}:
+		values := make([]*structpb.Value, len(v))
+		for i, item := range v {
+			values[i] = interfaceToValue(item)
+		}
+		return &structpb.Value{Kind: &structpb.Value_ListValue{ListValue: &structpb.ListValue{Values: values}}}
+	case []string:
+		values := make([]*structpb.Value, len(v))
+		for i, item := range v {
+			values[i] = interfaceToValue(item)
+		}
+		return &structpb.Value{Kind: &structpb.Value_ListValue{ListValue: &structpb.ListValue{Values: values}}}
+	case map[string]interface{}:
+		return &structpb.Value{Kind: &structpb.Value_StructValue{StructValue: mapToStruct(v)}}
+	default:
+		return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+}
@@ -0,0 +1,455 @@
+package dialogflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// yamlError is an error at a line and column of a YAML document, or at a line
+// if the column is 0.
+type yamlError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (err *yamlError) Error() string {
+	if err.Column == 0 {
+		return fmt.Sprintf("%d: %s", err.Line, err.Msg)
+	}
+	return fmt.Sprintf("%d:%d: %s", err.Line, err.Column, err.Msg)
+}
+
+// sourceError prefixes the error with the name of the source, as in
+// intents.yaml:3:5 for errors at a line and column.
+func sourceError(source Source, err error) error {
+	if _, ok := err.(*yamlError); ok {
+		return fmt.Errorf("%s:%v", source.Name(), err)
+	}
+	return fmt.Errorf("%s: %v", source.Name(), err)
+}
+
+var yamlLineErrorRegexp = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// decodeYAML decodes the YAML document into v after checking it against the
+// type of v: every key must be a field of v and every value must have the type
+// of its field. The document is read as YAML 1.2, so yes and no are strings,
+// and decoded through the JSON tags of v.
+func decodeYAML(data []byte, v interface{}) error {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		if m := yamlLineErrorRegexp.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return &yamlError{Line: line, Msg: m[2]}
+		}
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	t := reflect.TypeOf(v).Elem()
+	if err := checkYAMLNode(doc.Content[0], t); err != nil {
+		return err
+	}
+	value, err := yamlNodeValue(doc.Content[0], t)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// yamlListLines returns the lines of the items of the list under the key of
+// the YAML document.
+func yamlListLines(data []byte, key string) []int {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != key {
+			continue
+		}
+		var lines []int
+		for _, item := range root.Content[i+1].Content {
+			lines = append(lines, item.Line)
+		}
+		return lines
+	}
+	return nil
+}
+
+// yamlNodeValue returns the value of a checked node as a JSON value for the
+// type, with scalars of string fields as written, such as 1.0 or 2019-01-01.
+func yamlNodeValue(node *yamlv3.Node, t reflect.Type) (interface{}, error) {
+	if node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yamlv3.ScalarNode && node.Tag == "!!null" {
+		return nil, nil
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) && node.Kind == yamlv3.ScalarNode {
+		return node.Value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := schemaFields(t)
+		value := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			for _, field := range fields {
+				if field.Name != node.Content[i].Value {
+					continue
+				}
+				v, err := yamlNodeValue(node.Content[i+1], field.Type)
+				if err != nil {
+					return nil, err
+				}
+				value[field.Name] = v
+			}
+		}
+		return value, nil
+	case reflect.Slice:
+		value := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			v, err := yamlNodeValue(item, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			value = append(value, v)
+		}
+		return value, nil
+	case reflect.Map:
+		value := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v, err := yamlNodeValue(node.Content[i+1], t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			value[node.Content[i].Value] = v
+		}
+		return value, nil
+	case reflect.Interface:
+		switch node.Kind {
+		case yamlv3.MappingNode:
+			return yamlNodeValue(node, reflect.TypeOf(map[string]interface{}{}))
+		case yamlv3.SequenceNode:
+			return yamlNodeValue(node, reflect.TypeOf([]interface{}{}))
+		}
+	case reflect.String:
+		return node.Value, nil
+	}
+
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, yamlErrorf(node, "%v", err)
+	}
+	return value, nil
+}
+
+func yamlErrorf(node *yamlv3.Node, format string, args ...interface{}) error {
+	return &yamlError{Line: node.Line, Column: node.Column, Msg: fmt.Sprintf(format, args...)}
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// checkYAMLNode checks that the node can be decoded into a value of the type.
+func checkYAMLNode(node *yamlv3.Node, t reflect.Type) error {
+	if node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+	isNull := node.Kind == yamlv3.ScalarNode && node.Tag == "!!null"
+
+	if t.Kind() == reflect.Ptr {
+		if isNull {
+			return nil
+		}
+		t = t.Elem()
+	}
+	// Types with their own decoding, such as entity values, are written as
+	// a string or as an object.
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) && node.Kind == yamlv3.ScalarNode && !isNull {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yamlv3.MappingNode {
+			return yamlErrorf(node, "expected a mapping with %s", strings.Join(schemaFieldNames(t), ", "))
+		}
+		return checkYAMLMapping(node, t)
+	case reflect.Slice:
+		if isNull {
+			return nil
+		}
+		if node.Kind != yamlv3.SequenceNode {
+			return yamlErrorf(node, "expected a list")
+		}
+		for _, item := range node.Content {
+			if err := checkYAMLNode(item, t.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if isNull {
+			return nil
+		}
+		if node.Kind != yamlv3.MappingNode {
+			return yamlErrorf(node, "expected a mapping")
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := checkYAMLNode(node.Content[i], t.Elem()); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		if node.Kind != yamlv3.ScalarNode || isNull {
+			return yamlErrorf(node, "expected a string")
+		}
+	case reflect.Bool:
+		if node.Kind != yamlv3.ScalarNode || node.Tag != "!!bool" {
+			return yamlErrorf(node, "expected true or false, found %q", node.Value)
+		}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if node.Kind != yamlv3.ScalarNode || node.Tag != "!!int" {
+			return yamlErrorf(node, "expected an integer")
+		}
+	}
+	return nil
+}
+
+func checkYAMLMapping(node *yamlv3.Node, t reflect.Type) error {
+	fields := schemaFields(t)
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		var field *schemaField
+		for j := range fields {
+			if fields[j].Name == key.Value {
+				field = &fields[j]
+			}
+		}
+		if field == nil {
+			return yamlErrorf(key, "unknown field %q, expected one of %s", key.Value, strings.Join(schemaFieldNames(t), ", "))
+		}
+		if seen[key.Value] {
+			return yamlErrorf(key, "field %q is set more than once", key.Value)
+		}
+		seen[key.Value] = true
+
+		if err := checkYAMLNode(value, field.Type); err != nil {
+			return err
+		}
+		if len(field.Enum) > 0 && value.Kind == yamlv3.ScalarNode && !containsString(field.Enum, value.Value) {
+			return yamlErrorf(value, "unknown %s %q, expected one of %s", field.Name, value.Value, strings.Join(field.Enum, ", "))
+		}
+	}
+
+	for _, field := range fields {
+		if field.Required && !seen[field.Name] {
+			return yamlErrorf(node, "missing field %q", field.Name)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaField is a field of a struct in the YAML format, described by its JSON
+// tag and by an enum tag that lists its values.
+type schemaField struct {
+	Name     string
+	Type     reflect.Type
+	Required bool
+	Enum     []string
+}
+
+func schemaFields(t reflect.Type) []schemaField {
+	var fields []schemaField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		field := schemaField{
+			Name:     parts[0],
+			Type:     f.Type,
+			Required: true,
+		}
+		for _, option := range parts[1:] {
+			if option == "omitempty" {
+				field.Required = false
+			}
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			field.Enum = strings.Split(enum, ",")
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func schemaFieldNames(t reflect.Type) []string {
+	var names []string
+	for _, field := range schemaFields(t) {
+		names = append(names, field.Name)
+	}
+	return names
+}
+
+// jsonSchema returns the JSON Schema of the YAML format of the type.
+func jsonSchema(t reflect.Type, title string) ([]byte, error) {
+	definitions := make(map[string]interface{})
+	schema := structSchema(t, definitions)
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = title
+	schema["definitions"] = definitions
+
+	b, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func typeSchema(t reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		name := definitionName(t)
+		if _, ok := definitions[name]; !ok {
+			// Set the definition before it is built, for types that
+			// contain themselves such as followup intents.
+			definitions[name] = nil
+			definitions[name] = structSchema(t, definitions)
+		}
+		ref := map[string]interface{}{"$ref": "#/definitions/" + name}
+		if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+			return map[string]interface{}{
+				"oneOf": []interface{}{map[string]interface{}{"type": "string"}, ref},
+			}
+		}
+		return ref
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  []string{"array", "null"},
+			"items": typeSchema(t.Elem(), definitions),
+		}
+	case reflect.Map:
+		schema := map[string]interface{}{"type": []string{"object", "null"}}
+		if t.Elem().Kind() != reflect.Interface {
+			schema["additionalProperties"] = typeSchema(t.Elem(), definitions)
+		}
+		return schema
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func structSchema(t reflect.Type, definitions map[string]interface{}) map[string]interface{} {
+	var (
+		properties = make(map[string]interface{})
+		required   []string
+	)
+	for _, field := range schemaFields(t) {
+		schema := typeSchema(field.Type, definitions)
+		if len(field.Enum) > 0 {
+			schema["enum"] = field.Enum
+		}
+		properties[field.Name] = schema
+		if field.Required {
+			required = append(required, field.Name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// definitionName returns the name of the definition of a type, such as intent
+// for intentData.
+func definitionName(t reflect.Type) string {
+	name := []rune(strings.TrimSuffix(t.Name(), "Data"))
+	name[0] = unicode.ToLower(name[0])
+	return string(name)
+}
+
+func intentsSchema() ([]byte, error) {
+	return jsonSchema(reflect.TypeOf(intentsData{}), "Dialogflow intents")
+}
+
+func entityTypesSchema() ([]byte, error) {
+	return jsonSchema(reflect.TypeOf(entityTypesData{}), "Dialogflow entities")
+}
+
+// ValidateIntents reads the intents of the source and checks them against the
+// limits, without calling the API.
+func ValidateIntents(ctx context.Context, source Source, limits Limits) error {
+	data, err := source.ReadAll(ctx)
+	if err != nil {
+		return fmt.Errorf("read data: %v", err)
+	}
+	intents, err := readIntents(data)
+	if err != nil {
+		return fmt.Errorf("read intents: %v", sourceError(source, err))
+	}
+	return checkLimits(intents, nil, limits)
+}
+
+// ValidateEntityTypes reads the entity types of the source and checks them
+// against the limits, without calling the API.
+func ValidateEntityTypes(ctx context.Context, source Source, limits Limits) error {
+	data, err := source.ReadAll(ctx)
+	if err != nil {
+		return fmt.Errorf("read data: %v", err)
+	}
+	entityTypes, err := readEntityTypes(data)
+	if err != nil {
+		return fmt.Errorf("read entity types: %v", sourceError(source, err))
+	}
+	return checkLimits(nil, entityTypes, limits)
+}
@@ -0,0 +1,128 @@
+package dialogflow
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+var updateSchema = flag.Bool("update", false, "update the JSON schemas in the schema directory")
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		filename string
+		schema   func() ([]byte, error)
+	}{
+		{filename: "../schema/intents.schema.json", schema: intentsSchema},
+		{filename: "../schema/entities.schema.json", schema: entityTypesSchema},
+	}
+
+	for _, test := range tests {
+		schema, err := test.schema()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if *updateSchema {
+			if err = ioutil.WriteFile(test.filename, schema, 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		data, err := ioutil.ReadFile(test.filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, schema) {
+			t.Errorf("%s is out of date, run go test ./dialogflow -run TestSchema -update", test.filename)
+		}
+	}
+}
+
+func TestReadIntentsStrict(t *testing.T) {
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{
+			data: "intents:\n  - name: Hello\n    usersay:\n      - Hi\n",
+			err:  `3:5: unknown field "usersay", expected one of name, usersays, responses, messages, contexts, parameters, followup, fallback, action, priority, events, webhook, webhookSlotFilling, mlDisabled, resetContexts, languages`,
+		},
+		{
+			data: "intents:\n  - usersays:\n      - Hi\n",
+			err:  `2:5: missing field "name"`,
+		},
+		{
+			data: "intents:\n  - name: Hello\n    priority: high\n",
+			err:  `3:15: expected an integer`,
+		},
+		{
+			data: "intents:\n  - name: Hello\n    contexts:\n      out:\n        - {name: order, lifespan: 2, ttl: 3}\n",
+			err:  `5:38: unknown field "ttl", expected one of name, lifespan`,
+		},
+		{
+			data: "intents:\n  - name: Hello\n\tusersays: Hi\n",
+			err:  `2: found a tab character that violates indentation`,
+		},
+		{
+			data: "intents:\n  - name: Hello\n    webhook: yes\n",
+			err:  `3:14: expected true or false, found "yes"`,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := readIntents([]byte(test.data))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("expected error %q, got %v", test.err, err)
+		}
+	}
+}
+
+func TestReadIntentsYAML12(t *testing.T) {
+	data := "intents:\n  - name: Confirm\n    usersays: [yes, no, on, 1.0, 2019-01-01]\n    responses: [Off]\n    webhook: true\n"
+
+	intents, err := readIntents([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var phrases []string
+	for _, trainingPhrase := range intents[0].TrainingPhrases {
+		phrases = append(phrases, formatTrainingPhrase(trainingPhrase.Parts))
+	}
+	if expected := []string{"yes", "no", "on", "1.0", "2019-01-01"}; !reflect.DeepEqual(expected, phrases) {
+		t.Errorf("expected training phrases %q, got %q", expected, phrases)
+	}
+	if expected := []string{"Off"}; !reflect.DeepEqual(expected, intents[0].Messages[0].Text) {
+		t.Errorf("expected responses %q, got %q", expected, intents[0].Messages[0].Text)
+	}
+	if intents[0].WebhookState != "WEBHOOK_STATE_ENABLED" {
+		t.Errorf("expected webhook enabled, got %s", intents[0].WebhookState)
+	}
+}
+
+func TestReadEntityTypesStrict(t *testing.T) {
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{
+			data: "entities:\n  - type: size\n    kind: enum\n    values: [small]\n",
+			err:  `3:11: unknown kind "enum", expected one of map, list, regexp`,
+		},
+		{
+			data: "entities:\n  - type: size\n    values:\n      - {value: small, synonym: [s]}\n",
+			err:  `4:24: unknown field "synonym", expected one of value, synonyms`,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := readEntityTypes([]byte(test.data))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("expected error %q, got %v", test.err, err)
+		}
+	}
+}
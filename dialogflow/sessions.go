@@ -0,0 +1,214 @@
+package dialogflow
+
+import (
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
+	"google.golang.org/genproto/googleapis/type/latlng"
+)
+
+// DetectIntentOptions are the query parameters of a detect intent request.
+// The zero value sends none.
+type DetectIntentOptions struct {
+	// Contexts are activated before the query is matched. Context names
+	// may be short, such as order, or full context names.
+	Contexts []Context
+	// ResetContexts deletes the contexts of the session before the
+	// contexts are activated.
+	ResetContexts bool
+	// Payload is passed to the webhook in the original detect intent
+	// request payload.
+	Payload map[string]interface{}
+	// TimeZone is the time zone of the query, such as Europe/Paris, or
+	// empty for the time zone of the agent.
+	TimeZone    string
+	GeoLocation *GeoLocation
+	// SessionEntityTypes override or supplement the entity types of the
+	// agent for the session.
+	SessionEntityTypes []SessionEntityType
+	// AnalyzeSentiment requests the sentiment of the query text.
+	AnalyzeSentiment bool
+}
+
+type GeoLocation struct {
+	Latitude  float64
+	Longitude float64
+}
+
+type SessionEntityType struct {
+	// Name is the display name of the entity type, or the full name of the
+	// session entity type.
+	Name string
+	// EntityOverrideMode is ENTITY_OVERRIDE_MODE_OVERRIDE, the default, or
+	// ENTITY_OVERRIDE_MODE_SUPPLEMENT.
+	EntityOverrideMode string
+	Entities           []Entity
+}
+
+// Sentiment is the sentiment of a query text, with a score from -1 to 1 and
+// a magnitude of 0 or more.
+type Sentiment struct {
+	Score     float32
+	Magnitude float32
+}
+
+// AudioFile is speech in a WAV, FLAC or OGG Opus file. The encoding and sample
+// rate are read from the file.
+type AudioFile struct {
+	Filename     string
+	LanguageCode string
+	// OutputFilename is the file the synthesized speech of the response is
+	// saved to, encoded by its extension: .wav, .mp3 or .ogg. It is not
+	// saved if empty.
+	OutputFilename string
+}
+
+// RecognitionResult is the text recognized so far in streamed speech.
+type RecognitionResult struct {
+	Transcript string
+	// IsFinal is whether the transcript will not change anymore.
+	IsFinal bool
+	// Confidence is the confidence of a final transcript, or 0 if not known.
+	Confidence float32
+	// EndOfSingleUtterance is whether the end of the speech was detected,
+	// after which no more audio is recognized.
+	EndOfSingleUtterance bool
+}
+
+// DetectIntentResult is the result of a detect intent request.
+type DetectIntentResult struct {
+	ResponseID   string
+	QueryText    string
+	LanguageCode string
+	// SpeechRecognitionConfidence is the confidence of the query text
+	// recognized from audio, or 0 if not known.
+	SpeechRecognitionConfidence float32
+	// Intent is the matched intent, with its name, display name and whether
+	// it is a fallback intent, or nil if no intent matched.
+	Intent                    *Intent
+	IntentDetectionConfidence float32
+	Action                    string
+	Parameters                map[string]interface{}
+	AllRequiredParamsPresent  bool
+	FulfillmentText           string
+	FulfillmentMessages       []Message
+	OutputContexts            []Context
+	DiagnosticInfo            map[string]interface{}
+	WebhookSource             string
+	WebhookPayload            map[string]interface{}
+	// WebhookStatus is the status of the webhook call, or nil if the
+	// webhook was not called.
+	WebhookStatus *WebhookStatus
+	// QueryTextSentiment is the sentiment of the query text, or nil if it
+	// was not analyzed.
+	QueryTextSentiment *Sentiment
+	// OutputAudio is the synthesized speech of the response, if it was
+	// requested.
+	OutputAudio []byte
+}
+
+type WebhookStatus struct {
+	Code    int32
+	Message string
+}
+
+func dialogflowResponseToDetectIntentResult(response *dialogflowpb.DetectIntentResponse) DetectIntentResult {
+	queryResult := response.GetQueryResult()
+
+	result := DetectIntentResult{
+		ResponseID:                  response.GetResponseId(),
+		QueryText:                   queryResult.GetQueryText(),
+		LanguageCode:                queryResult.GetLanguageCode(),
+		SpeechRecognitionConfidence: queryResult.GetSpeechRecognitionConfidence(),
+		IntentDetectionConfidence:   queryResult.GetIntentDetectionConfidence(),
+		Action:                      queryResult.GetAction(),
+		Parameters:                  structToMap(queryResult.GetParameters()),
+		AllRequiredParamsPresent:    queryResult.GetAllRequiredParamsPresent(),
+		FulfillmentText:             queryResult.GetFulfillmentText(),
+		FulfillmentMessages:         toMessages(queryResult.GetFulfillmentMessages()),
+		OutputContexts:              toContexts(queryResult.GetOutputContexts()),
+		DiagnosticInfo:              structToMap(queryResult.GetDiagnosticInfo()),
+		WebhookSource:               queryResult.GetWebhookSource(),
+		WebhookPayload:              structToMap(queryResult.GetWebhookPayload()),
+		OutputAudio:                 response.GetOutputAudio(),
+	}
+	if queryResult.GetIntent() != nil {
+		intent := dialogflowIntentToIntent(queryResult.GetIntent())
+		result.Intent = &intent
+	}
+	if status := response.GetWebhookStatus(); status != nil {
+		result.WebhookStatus = &WebhookStatus{Code: status.Code, Message: status.Message}
+	}
+	if sentiment := queryResult.GetSentimentAnalysisResult().GetQueryTextSentiment(); sentiment != nil {
+		result.QueryTextSentiment = &Sentiment{Score: sentiment.Score, Magnitude: sentiment.Magnitude}
+	}
+
+	return result
+}
+
+// toDialogflowQueryParameters returns the query parameters of the options,
+// with short context and entity type names expanded, or nil if there are none.
+func (client *SessionsClient) toDialogflowQueryParameters(sessionID string, opts DetectIntentOptions) *dialogflowpb.QueryParameters {
+	queryParams := &dialogflowpb.QueryParameters{
+		TimeZone:      opts.TimeZone,
+		ResetContexts: opts.ResetContexts,
+	}
+
+	contexts := make([]Context, len(opts.Contexts))
+	for i, c := range opts.Contexts {
+		if !strings.Contains(c.Name, "/") {
+			c.Name = client.ContextName(sessionID, c.Name)
+		}
+		contexts[i] = c
+	}
+	queryParams.Contexts = toDialogflowContexts(contexts)
+
+	for _, sessionEntityType := range opts.SessionEntityTypes {
+		name := sessionEntityType.Name
+		if !strings.Contains(name, "/") {
+			name = client.SessionEntityTypeName(sessionID, name)
+		}
+		queryParams.SessionEntityTypes = append(queryParams.SessionEntityTypes, &dialogflowpb.SessionEntityType{
+			Name:               name,
+			EntityOverrideMode: toDialogflowEntityOverrideMode(sessionEntityType.EntityOverrideMode),
+			Entities:           toDialogflowEntities(sessionEntityType.Entities),
+		})
+	}
+
+	if opts.Payload != nil {
+		queryParams.Payload = mapToStruct(opts.Payload)
+	}
+	if opts.GeoLocation != nil {
+		queryParams.GeoLocation = &latlng.LatLng{
+			Latitude:  opts.GeoLocation.Latitude,
+			Longitude: opts.GeoLocation.Longitude,
+		}
+	}
+	if opts.AnalyzeSentiment {
+		queryParams.SentimentAnalysisRequestConfig = &dialogflowpb.SentimentAnalysisRequestConfig{
+			AnalyzeQueryTextSentiment: true,
+		}
+	}
+
+	if proto.Equal(queryParams, &dialogflowpb.QueryParameters{}) {
+		return nil
+	}
+	return queryParams
+}
+
+func toDialogflowEntityOverrideMode(entityOverrideMode string) dialogflowpb.SessionEntityType_EntityOverrideMode {
+	if val, ok := dialogflowpb.SessionEntityType_EntityOverrideMode_value[entityOverrideMode]; ok && val != 0 {
+		return dialogflowpb.SessionEntityType_EntityOverrideMode(val)
+	}
+	return dialogflowpb.SessionEntityType_ENTITY_OVERRIDE_MODE_OVERRIDE
+}
+
+func toRecognitionResult(result *dialogflowpb.StreamingRecognitionResult) RecognitionResult {
+	return RecognitionResult{
+		Transcript:           result.Transcript,
+		IsFinal:              result.IsFinal,
+		Confidence:           result.Confidence,
+		EndOfSingleUtterance: result.MessageType == dialogflowpb.StreamingRecognitionResult_END_OF_SINGLE_UTTERANCE,
+	}
+}
@@ -1,23 +1,36 @@
 package dialogflow
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 
 	"cloud.google.com/go/dialogflow/apiv2"
 	"google.golang.org/api/option"
 	dialogflowpb "google.golang.org/genproto/googleapis/cloud/dialogflow/v2"
 )
 
+// SessionsAPI is the interface of the sessions client, so that code using it
+// can be tested with a fake.
+type SessionsAPI interface {
+	DetectIntentText(ctx context.Context, sessionID, text, languageCode string, opts DetectIntentOptions) (DetectIntentResult, error)
+	DetectIntentEvent(ctx context.Context, sessionID, name string, params map[string]interface{}, languageCode string, opts DetectIntentOptions) (DetectIntentResult, error)
+	DetectIntentAudio(ctx context.Context, sessionID string, audio AudioFile, opts DetectIntentOptions) (DetectIntentResult, error)
+	StreamingDetectIntent(ctx context.Context, sessionID string, audio io.Reader, languageCode string, opts DetectIntentOptions, recognitionResults chan<- RecognitionResult) (DetectIntentResult, error)
+}
+
+var _ SessionsAPI = (*SessionsClient)(nil)
+
 type SessionsClient struct {
 	projectID      string
 	sessionsClient *dialogflow.SessionsClient
 }
 
-func NewSessionsClient(projectID, credentialsFile string) (*SessionsClient, error) {
-	ctx := context.Background()
-
-	sessionClient, err := dialogflow.NewSessionsClient(ctx, option.WithCredentialsFile(credentialsFile))
+func NewSessionsClient(ctx context.Context, projectID string, opts ...option.ClientOption) (*SessionsClient, error) {
+	sessionClient, err := dialogflow.NewSessionsClient(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create sessions client: %v", err)
 	}
@@ -25,23 +38,246 @@ func NewSessionsClient(projectID, credentialsFile string) (*SessionsClient, erro
 	return &SessionsClient{projectID: projectID, sessionsClient: sessionClient}, nil
 }
 
-func (client *SessionsClient) DetectIntentText(sessionID, text, languageCode string) (string, error) {
-	ctx := context.Background()
-
-	sessionPath := fmt.Sprintf("projects/%s/agent/sessions/%s", client.projectID, sessionID)
+func (client *SessionsClient) DetectIntentText(ctx context.Context, sessionID, text, languageCode string, opts DetectIntentOptions) (DetectIntentResult, error) {
 	textInput := dialogflowpb.TextInput{Text: text, LanguageCode: languageCode}
 	queryTextInput := dialogflowpb.QueryInput_Text{Text: &textInput}
 	queryInput := dialogflowpb.QueryInput{Input: &queryTextInput}
-	request := dialogflowpb.DetectIntentRequest{Session: sessionPath, QueryInput: &queryInput}
 
-	response, err := client.sessionsClient.DetectIntent(ctx, &request)
+	return client.DetectIntent(ctx, sessionID, &queryInput, opts)
+}
+
+// DetectIntentEvent triggers the intents of the event with the parameters,
+// which may be nil.
+func (client *SessionsClient) DetectIntentEvent(ctx context.Context, sessionID, name string, params map[string]interface{}, languageCode string, opts DetectIntentOptions) (DetectIntentResult, error) {
+	eventInput := dialogflowpb.EventInput{Name: name, LanguageCode: languageCode}
+	if params != nil {
+		eventInput.Parameters = mapToStruct(params)
+	}
+	queryEventInput := dialogflowpb.QueryInput_Event{Event: &eventInput}
+	queryInput := dialogflowpb.QueryInput{Input: &queryEventInput}
+
+	return client.DetectIntent(ctx, sessionID, &queryInput, opts)
+}
+
+// DetectIntentAudio sends the speech in the audio file to the session, and
+// saves the synthesized speech of the response if the audio file has an
+// output file. It returns an error if the response has no speech to save.
+func (client *SessionsClient) DetectIntentAudio(ctx context.Context, sessionID string, audio AudioFile, opts DetectIntentOptions) (DetectIntentResult, error) {
+	data, err := ioutil.ReadFile(audio.Filename)
+	if err != nil {
+		return DetectIntentResult{}, fmt.Errorf("read audio file: %v", err)
+	}
+	format, err := readAudioFormat(data)
+	if err != nil {
+		return DetectIntentResult{}, fmt.Errorf("%s: %v", audio.Filename, err)
+	}
+
+	request := dialogflowpb.DetectIntentRequest{
+		Session: client.sessionPath(sessionID),
+		QueryInput: &dialogflowpb.QueryInput{
+			Input: &dialogflowpb.QueryInput_AudioConfig{
+				AudioConfig: &dialogflowpb.InputAudioConfig{
+					AudioEncoding:   format.Encoding,
+					SampleRateHertz: format.SampleRateHertz,
+					LanguageCode:    audio.LanguageCode,
+				},
+			},
+		},
+		QueryParams: client.toDialogflowQueryParameters(sessionID, opts),
+		InputAudio:  data,
+	}
+	if audio.OutputFilename != "" {
+		encoding, err := outputAudioEncoding(audio.OutputFilename)
+		if err != nil {
+			return DetectIntentResult{}, err
+		}
+		request.OutputAudioConfig = &dialogflowpb.OutputAudioConfig{AudioEncoding: encoding}
+	}
+
+	result, err := client.detectIntent(ctx, &request)
+	if err != nil {
+		return DetectIntentResult{}, err
+	}
+
+	if audio.OutputFilename != "" {
+		if len(result.OutputAudio) == 0 {
+			return DetectIntentResult{}, errors.New("write output audio: response has no output audio")
+		}
+		if err = ioutil.WriteFile(audio.OutputFilename, result.OutputAudio, 0644); err != nil {
+			return DetectIntentResult{}, fmt.Errorf("write output audio: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+// streamingChunkSize is the size of the audio chunks that are streamed, about
+// 100 milliseconds of 16-bit audio at 16 kHz.
+const streamingChunkSize = 3200
+
+// StreamingDetectIntent streams the speech in the WAV, FLAC or OGG Opus audio
+// to the session in chunks, as a phone call would. The recognition results
+// are sent on the channel while the audio is streamed, which is closed when
+// the stream ends, unless it is nil. The result of the query follows once the
+// audio ends.
+func (client *SessionsClient) StreamingDetectIntent(ctx context.Context, sessionID string, audio io.Reader, languageCode string, opts DetectIntentOptions, recognitionResults chan<- RecognitionResult) (DetectIntentResult, error) {
+	if recognitionResults != nil {
+		defer close(recognitionResults)
+	}
+
+	r := bufio.NewReaderSize(audio, audioHeaderSize)
+	header, err := r.Peek(audioHeaderSize)
+	if err != nil && err != io.EOF {
+		return DetectIntentResult{}, fmt.Errorf("read audio: %v", err)
+	}
+	format, err := readAudioFormat(header)
+	if err != nil {
+		return DetectIntentResult{}, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.sessionsClient.StreamingDetectIntent(ctx)
+	if err != nil {
+		return DetectIntentResult{}, fmt.Errorf("failed to stream detect intent: %v", err)
+	}
+
+	err = stream.Send(&dialogflowpb.StreamingDetectIntentRequest{
+		Session: client.sessionPath(sessionID),
+		QueryInput: &dialogflowpb.QueryInput{
+			Input: &dialogflowpb.QueryInput_AudioConfig{
+				AudioConfig: &dialogflowpb.InputAudioConfig{
+					AudioEncoding:   format.Encoding,
+					SampleRateHertz: format.SampleRateHertz,
+					LanguageCode:    languageCode,
+				},
+			},
+		},
+		QueryParams: client.toDialogflowQueryParameters(sessionID, opts),
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to detect intent client: %v", err)
+		return DetectIntentResult{}, fmt.Errorf("failed to stream detect intent: %v", err)
+	}
+
+	// The audio is sent while the responses are received, so that recognition
+	// results arrive during the audio. A failed read cancels the stream.
+	sendErr := make(chan error, 1)
+	go func() {
+		err := sendAudio(stream, r)
+		sendErr <- err
+		if err != nil {
+			cancel()
+		}
+	}()
+
+	var response *dialogflowpb.StreamingDetectIntentResponse
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			select {
+			case err := <-sendErr:
+				if err != nil {
+					return DetectIntentResult{}, err
+				}
+			default:
+			}
+			return DetectIntentResult{}, fmt.Errorf("failed to stream detect intent: %v", err)
+		}
+
+		if result := resp.GetRecognitionResult(); result != nil && recognitionResults != nil {
+			select {
+			case recognitionResults <- toRecognitionResult(result):
+			case <-ctx.Done():
+				return DetectIntentResult{}, ctx.Err()
+			}
+		}
+		if resp.GetQueryResult() != nil {
+			response = resp
+		}
+	}
+	if err := <-sendErr; err != nil {
+		return DetectIntentResult{}, err
+	}
+
+	if response == nil {
+		return DetectIntentResult{}, errors.New("stream ended without a query result")
+	}
+	return dialogflowResponseToDetectIntentResult(&dialogflowpb.DetectIntentResponse{
+		ResponseId:        response.ResponseId,
+		QueryResult:       response.QueryResult,
+		WebhookStatus:     response.WebhookStatus,
+		OutputAudio:       response.OutputAudio,
+		OutputAudioConfig: response.OutputAudioConfig,
+	}), nil
+}
+
+// sendAudio sends the audio in chunks and closes the sending side of the
+// stream.
+func sendAudio(stream dialogflowpb.Sessions_StreamingDetectIntentClient, r io.Reader) error {
+	buf := make([]byte, streamingChunkSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			// Send fails with io.EOF when the server ended the stream,
+			// and Recv returns the reason.
+			if err := stream.Send(&dialogflowpb.StreamingDetectIntentRequest{InputAudio: chunk}); err != nil {
+				return nil
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read audio: %v", err)
+		}
 	}
+	if err := stream.CloseSend(); err != nil {
+		return fmt.Errorf("failed to stream detect intent: %v", err)
+	}
+	return nil
+}
+
+// DetectIntent sends the query input with the query parameters of the options
+// to the session.
+func (client *SessionsClient) DetectIntent(ctx context.Context, sessionID string, queryInput *dialogflowpb.QueryInput, opts DetectIntentOptions) (DetectIntentResult, error) {
+	request := dialogflowpb.DetectIntentRequest{
+		Session:     client.sessionPath(sessionID),
+		QueryInput:  queryInput,
+		QueryParams: client.toDialogflowQueryParameters(sessionID, opts),
+	}
+
+	return client.detectIntent(ctx, &request)
+}
+
+func (client *SessionsClient) detectIntent(ctx context.Context, request *dialogflowpb.DetectIntentRequest) (DetectIntentResult, error) {
+	response, err := client.sessionsClient.DetectIntent(ctx, request)
+	if err != nil {
+		return DetectIntentResult{}, fmt.Errorf("failed to detect intent client: %v", err)
+	}
+
+	return dialogflowResponseToDetectIntentResult(response), nil
+}
+
+// ContextName returns the full name of a context of the session, such as
+// projects/example/agent/sessions/123/contexts/order for order.
+func (client *SessionsClient) ContextName(sessionID, name string) string {
+	return fmt.Sprintf("%s/contexts/%s", client.sessionPath(sessionID), name)
+}
+
+// SessionEntityTypeName returns the full name of a session entity type, such
+// as projects/example/agent/sessions/123/entityTypes/size for size.
+func (client *SessionsClient) SessionEntityTypeName(sessionID, displayName string) string {
+	return fmt.Sprintf("%s/entityTypes/%s", client.sessionPath(sessionID), displayName)
+}
 
-	queryResult := response.GetQueryResult()
-	fulfillmentText := queryResult.GetFulfillmentText()
-	return fulfillmentText, err
+func (client *SessionsClient) sessionPath(sessionID string) string {
+	return fmt.Sprintf("projects/%s/agent/sessions/%s", client.projectID, sessionID)
 }
 
 func (client *SessionsClient) Close() error {
@@ -0,0 +1,494 @@
+package dialogflow_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow"
+	"github.com/nicovogelaar/dialogflow-agent/dialogflow/dialogflowtest"
+)
+
+func TestDetectIntentText(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err := importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionsClient, err := dialogflow.NewSessionsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sessionsClient.Close()
+
+	tests := []struct {
+		text     string
+		intent   string
+		expected string
+	}{
+		{text: "How are you?", intent: "How are you?", expected: "I'm great, thanks."},
+		{text: "not too bad", intent: "I am good", expected: "Great"},
+		{text: "What time is it?", expected: ""},
+	}
+
+	for _, test := range tests {
+		result, err := sessionsClient.DetectIntentText(context.Background(), "session", test.text, "en", dialogflow.DetectIntentOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.FulfillmentText != test.expected {
+			t.Errorf("expected %q for %q, got %q", test.expected, test.text, result.FulfillmentText)
+		}
+		var intent string
+		if result.Intent != nil {
+			intent = result.Intent.DisplayName
+		}
+		if intent != test.intent {
+			t.Errorf("expected intent %q for %q, got %q", test.intent, test.text, intent)
+		}
+	}
+}
+
+func TestDetectIntentTextResult(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err := importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionsClient, err := dialogflow.NewSessionsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sessionsClient.Close()
+
+	result, err := sessionsClient.DetectIntentText(context.Background(), "session", "How are you?", "en", dialogflow.DetectIntentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.QueryText != "How are you?" || result.LanguageCode != "en" {
+		t.Errorf("expected query text and language code, got %q and %q", result.QueryText, result.LanguageCode)
+	}
+	if result.IntentDetectionConfidence != 1 {
+		t.Errorf("expected confidence 1, got %v", result.IntentDetectionConfidence)
+	}
+	if result.Action != "smalltalk.how-are-you" {
+		t.Errorf("expected action smalltalk.how-are-you, got %q", result.Action)
+	}
+
+	expectedContexts := []dialogflow.Context{
+		{Name: "projects/example/agent/sessions/session/contexts/how-are-you", LifespanCount: 2},
+	}
+	if !reflect.DeepEqual(expectedContexts, result.OutputContexts) {
+		t.Errorf("expected output contexts %+v, got %+v", expectedContexts, result.OutputContexts)
+	}
+	if len(result.FulfillmentMessages) != 5 {
+		t.Errorf("expected 5 fulfillment messages, got %d", len(result.FulfillmentMessages))
+	}
+	if result.WebhookStatus != nil {
+		t.Errorf("expected no webhook status, got %+v", result.WebhookStatus)
+	}
+
+	result, err = sessionsClient.DetectIntentText(context.Background(), "session", "Hi, my name is John", "en", dialogflow.DetectIntentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]interface{}{"name": "John"}; !reflect.DeepEqual(expected, result.Parameters) {
+		t.Errorf("expected parameters %v, got %v", expected, result.Parameters)
+	}
+}
+
+func TestDetectIntentTextOptions(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	sessionsClient, err := dialogflow.NewSessionsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sessionsClient.Close()
+
+	opts := dialogflow.DetectIntentOptions{
+		Contexts:      []dialogflow.Context{{Name: "order", LifespanCount: 2}},
+		ResetContexts: true,
+		Payload:       map[string]interface{}{"source": "test"},
+		TimeZone:      "Europe/Amsterdam",
+		GeoLocation:   &dialogflow.GeoLocation{Latitude: 52.37, Longitude: 4.89},
+		SessionEntityTypes: []dialogflow.SessionEntityType{{
+			Name:     "size",
+			Entities: []dialogflow.Entity{{Value: "xl", Synonyms: []string{"xl", "extra large"}}},
+		}},
+		AnalyzeSentiment: true,
+	}
+	result, err := sessionsClient.DetectIntentText(context.Background(), "session", "Hello", "en", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (&dialogflow.Sentiment{}); !reflect.DeepEqual(expected, result.QueryTextSentiment) {
+		t.Errorf("expected sentiment %+v, got %+v", expected, result.QueryTextSentiment)
+	}
+
+	requests := server.DetectIntentRequests()
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	queryParams := requests[0].QueryParams
+
+	if name := queryParams.GetContexts()[0].Name; name != "projects/example/agent/sessions/session/contexts/order" {
+		t.Errorf("expected full context name, got %q", name)
+	}
+	if !queryParams.ResetContexts {
+		t.Error("expected contexts to be reset")
+	}
+	if source := queryParams.GetPayload().GetFields()["source"].GetStringValue(); source != "test" {
+		t.Errorf("expected payload source test, got %q", source)
+	}
+	if queryParams.TimeZone != "Europe/Amsterdam" {
+		t.Errorf("expected time zone Europe/Amsterdam, got %q", queryParams.TimeZone)
+	}
+	if geoLocation := queryParams.GetGeoLocation(); geoLocation.GetLatitude() != 52.37 || geoLocation.GetLongitude() != 4.89 {
+		t.Errorf("expected geo-location 52.37,4.89, got %v", geoLocation)
+	}
+	sessionEntityType := queryParams.GetSessionEntityTypes()[0]
+	if sessionEntityType.Name != "projects/example/agent/sessions/session/entityTypes/size" {
+		t.Errorf("expected full session entity type name, got %q", sessionEntityType.Name)
+	}
+	if mode := sessionEntityType.EntityOverrideMode.String(); mode != "ENTITY_OVERRIDE_MODE_OVERRIDE" {
+		t.Errorf("expected override mode, got %s", mode)
+	}
+	if len(sessionEntityType.Entities) != 1 || sessionEntityType.Entities[0].Value != "xl" {
+		t.Errorf("expected entity xl, got %v", sessionEntityType.Entities)
+	}
+
+	if _, err = sessionsClient.DetectIntentText(context.Background(), "session", "Hello", "en", dialogflow.DetectIntentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if queryParams := server.DetectIntentRequests()[1].QueryParams; queryParams != nil {
+		t.Errorf("expected no query parameters without options, got %v", queryParams)
+	}
+}
+
+func TestDetectIntentEvent(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	source, remove := newTestSource(t, `
+intents:
+  - name: Welcome
+    events:
+      - WELCOME
+    responses:
+      - Welcome!
+`)
+	defer remove()
+
+	if err := dialogflow.NewIntentsImporter(intentsClient, source).ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionsClient, err := dialogflow.NewSessionsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sessionsClient.Close()
+
+	params := map[string]interface{}{"name": "John"}
+	result, err := sessionsClient.DetectIntentEvent(context.Background(), "session", "WELCOME", params, "en", dialogflow.DetectIntentOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Intent == nil || result.Intent.DisplayName != "Welcome" || result.FulfillmentText != "Welcome!" {
+		t.Errorf("expected the Welcome intent, got %+v", result)
+	}
+
+	event := server.DetectIntentRequests()[0].QueryInput.GetEvent()
+	if event.GetName() != "WELCOME" || event.GetLanguageCode() != "en" {
+		t.Errorf("expected event WELCOME in en, got %v", event)
+	}
+	if name := event.GetParameters().GetFields()["name"].GetStringValue(); name != "John" {
+		t.Errorf("expected parameter name John, got %q", name)
+	}
+}
+
+func TestDetectIntentAudio(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err := importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionsClient, err := dialogflow.NewSessionsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sessionsClient.Close()
+
+	dir, err := ioutil.TempDir("", "dialogflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		filename   string
+		data       []byte
+		encoding   string
+		sampleRate int32
+		err        string
+	}{
+		{filename: "speech.wav", data: testWAV(1, 1, 16000, 16), encoding: "AUDIO_ENCODING_LINEAR_16", sampleRate: 16000},
+		{filename: "speech-mulaw.wav", data: testWAV(7, 1, 8000, 8), encoding: "AUDIO_ENCODING_MULAW", sampleRate: 8000},
+		{filename: "speech.flac", data: testFLAC(16000, 1), encoding: "AUDIO_ENCODING_FLAC", sampleRate: 16000},
+		{filename: "speech.ogg", data: testOggOpus(16000, 1), encoding: "AUDIO_ENCODING_OGG_OPUS", sampleRate: 16000},
+		{filename: "speech-44k.ogg", data: testOggOpus(44100, 1), encoding: "AUDIO_ENCODING_OGG_OPUS", sampleRate: 48000},
+		{filename: "stereo.wav", data: testWAV(1, 2, 16000, 16), err: "WAV audio has 2 channels, must be mono"},
+		{filename: "stereo.flac", data: testFLAC(16000, 2), err: "FLAC audio has 2 channels, must be mono"},
+		{filename: "speech.mp3", data: []byte("ID3"), err: "unknown audio format, must be WAV, FLAC or OGG Opus"},
+		{filename: "truncated.ogg", data: testTruncatedOgg(), err: "OGG audio has no first page"},
+	}
+
+	for _, test := range tests {
+		filename := filepath.Join(dir, test.filename)
+		if err = ioutil.WriteFile(filename, test.data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		server.SetTranscript(test.data, "How are you?")
+
+		result, err := sessionsClient.DetectIntentAudio(context.Background(), "session", dialogflow.AudioFile{Filename: filename, LanguageCode: "en"}, dialogflow.DetectIntentOptions{})
+		if test.err != "" {
+			if expected := filename + ": " + test.err; err == nil || err.Error() != expected {
+				t.Errorf("expected error %q for %s, got %v", expected, test.filename, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.QueryText != "How are you?" || result.FulfillmentText != "I'm great, thanks." {
+			t.Errorf("expected the How are you? intent for %s, got %+v", test.filename, result)
+		}
+
+		requests := server.DetectIntentRequests()
+		audioConfig := requests[len(requests)-1].QueryInput.GetAudioConfig()
+		if audioConfig.GetAudioEncoding().String() != test.encoding || audioConfig.GetSampleRateHertz() != test.sampleRate {
+			t.Errorf("expected %s at %d Hz for %s, got %v", test.encoding, test.sampleRate, test.filename, audioConfig)
+		}
+	}
+}
+
+func TestDetectIntentAudioOutput(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err := importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionsClient, err := dialogflow.NewSessionsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sessionsClient.Close()
+
+	dir, err := ioutil.TempDir("", "dialogflow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	data := testWAV(1, 1, 16000, 16)
+	server.SetTranscript(data, "How are you?")
+	audio := dialogflow.AudioFile{
+		Filename:       filepath.Join(dir, "speech.wav"),
+		LanguageCode:   "en",
+		OutputFilename: filepath.Join(dir, "response.mp3"),
+	}
+	if err = ioutil.WriteFile(audio.Filename, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = sessionsClient.DetectIntentAudio(context.Background(), "session", audio, dialogflow.DetectIntentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := ioutil.ReadFile(audio.OutputFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "I'm great, thanks." {
+		t.Errorf("expected the fake output audio, got %q", output)
+	}
+	if encoding := server.DetectIntentRequests()[0].OutputAudioConfig.GetAudioEncoding().String(); encoding != "OUTPUT_AUDIO_ENCODING_MP3" {
+		t.Errorf("expected MP3 output audio, got %s", encoding)
+	}
+
+	server.SetTranscript(data, "Something else entirely")
+	audio.OutputFilename = filepath.Join(dir, "empty.mp3")
+	if _, err = sessionsClient.DetectIntentAudio(context.Background(), "session", audio, dialogflow.DetectIntentOptions{}); err == nil {
+		t.Error("expected an error for a response without output audio")
+	}
+	if _, err = os.Stat(audio.OutputFilename); !os.IsNotExist(err) {
+		t.Errorf("expected no output audio file, got %v", err)
+	}
+
+	audio.OutputFilename = filepath.Join(dir, "response.aac")
+	if _, err = sessionsClient.DetectIntentAudio(context.Background(), "session", audio, dialogflow.DetectIntentOptions{}); err == nil {
+		t.Error("expected an error for an unknown output audio extension")
+	}
+}
+
+// testWAV returns the header of a WAV file with a few samples.
+func testWAV(format, channels uint16, sampleRate uint32, bitsPerSample uint16) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(36+8))
+	buf.WriteString("WAVEfmt ")
+	for _, v := range []interface{}{
+		uint32(16), format, channels, sampleRate,
+		sampleRate * uint32(channels*bitsPerSample/8), channels * bitsPerSample / 8, bitsPerSample,
+	} {
+		_ = binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(8))
+	buf.Write(make([]byte, 8))
+	return buf.Bytes()
+}
+
+// testFLAC returns the start of a FLAC file with its STREAMINFO block.
+func testFLAC(sampleRate uint32, channels byte) []byte {
+	streamInfo := make([]byte, 34)
+	streamInfo[10] = byte(sampleRate >> 12)
+	streamInfo[11] = byte(sampleRate >> 4)
+	streamInfo[12] = byte(sampleRate<<4) | (channels-1)<<1
+	return append([]byte{'f', 'L', 'a', 'C', 0x80, 0, 0, 34}, streamInfo...)
+}
+
+// testTruncatedOgg returns the start of an OGG file whose segment table is cut
+// off.
+func testTruncatedOgg() []byte {
+	data := testOggOpus(16000, 1)
+	data[26] = 200
+	return data[:40]
+}
+
+// testOggOpus returns the first page of an OGG Opus file.
+func testOggOpus(sampleRate uint32, channels byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("OggS")
+	buf.Write(make([]byte, 22))
+	buf.Write([]byte{1, 19})
+	buf.WriteString("OpusHead")
+	buf.Write([]byte{1, channels, 0, 0})
+	_ = binary.Write(&buf, binary.LittleEndian, sampleRate)
+	buf.Write([]byte{0, 0, 0})
+	return buf.Bytes()
+}
+
+func TestStreamingDetectIntent(t *testing.T) {
+	server := dialogflowtest.NewServer()
+	defer server.Close()
+
+	intentsClient := newTestIntentsClient(t, server)
+	defer intentsClient.Close()
+
+	importer := dialogflow.NewIntentsImporter(intentsClient, dialogflow.NewFileSource("../examples/intents.yaml"))
+	if err := importer.ImportIntents(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	sessionsClient, err := dialogflow.NewSessionsClient(context.Background(), "example", server.ClientOptions()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sessionsClient.Close()
+
+	// Four chunks of audio, for the three words of the transcript.
+	audio := append(testWAV(1, 1, 16000, 16), make([]byte, 4*3200-52)...)
+	server.SetTranscript(audio, "How are you?")
+
+	recognitionResults := make(chan dialogflow.RecognitionResult)
+	var received []dialogflow.RecognitionResult
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for result := range recognitionResults {
+			received = append(received, result)
+		}
+	}()
+
+	result, err := sessionsClient.StreamingDetectIntent(context.Background(), "session", bytes.NewReader(audio), "en", dialogflow.DetectIntentOptions{}, recognitionResults)
+	<-done
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []dialogflow.RecognitionResult{
+		{Transcript: "How"},
+		{Transcript: "How are"},
+		{Transcript: "How are you?"},
+		{Transcript: "How are you?", IsFinal: true, Confidence: 1},
+	}
+	if !reflect.DeepEqual(expected, received) {
+		t.Errorf("expected recognition results %+v, got %+v", expected, received)
+	}
+	if result.QueryText != "How are you?" || result.FulfillmentText != "I'm great, thanks." {
+		t.Errorf("expected the How are you? intent, got %+v", result)
+	}
+
+	request := server.DetectIntentRequests()[0]
+	if !bytes.Equal(request.InputAudio, audio) {
+		t.Errorf("expected the server to receive all %d bytes of audio, got %d", len(audio), len(request.InputAudio))
+	}
+	if encoding := request.QueryInput.GetAudioConfig().GetAudioEncoding().String(); encoding != "AUDIO_ENCODING_LINEAR_16" {
+		t.Errorf("expected linear 16 audio, got %s", encoding)
+	}
+
+	_, err = sessionsClient.StreamingDetectIntent(context.Background(), "session", strings.NewReader("not audio"), "en", dialogflow.DetectIntentOptions{}, nil)
+	if err == nil {
+		t.Error("expected an error for unknown audio")
+	}
+
+	_, err = sessionsClient.StreamingDetectIntent(context.Background(), "session", bytes.NewReader(testTruncatedOgg()), "en", dialogflow.DetectIntentOptions{}, nil)
+	if err == nil || err.Error() != "OGG audio has no first page" {
+		t.Errorf("expected an error for truncated audio, got %v", err)
+	}
+
+	failing := io.MultiReader(bytes.NewReader(audio), iotest.TimeoutReader(bytes.NewReader(audio)))
+	_, err = sessionsClient.StreamingDetectIntent(context.Background(), "session", failing, "en", dialogflow.DetectIntentOptions{}, nil)
+	if expected := "read audio: " + iotest.ErrTimeout.Error(); err == nil || err.Error() != expected {
+		t.Errorf("expected error %q for a failing read, got %v", expected, err)
+	}
+}
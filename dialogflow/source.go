@@ -2,14 +2,22 @@ package dialogflow
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 )
 
 type Source interface {
 	io.ReadCloser
+
+	// ReadAll reads all data of the source, giving up when ctx is done.
+	ReadAll(ctx context.Context) ([]byte, error)
+
+	// Name returns the filename or URL of the source.
+	Name() string
 }
 
 type fileSource struct {
@@ -26,31 +34,40 @@ func NewFileSource(filename string) Source {
 
 func (source *fileSource) Read(p []byte) (n int, err error) {
 	if source.buffer.Len() == 0 {
-		file, err := os.Open(source.filename)
+		data, err := source.ReadAll(context.Background())
 		if err != nil {
-			return 0, fmt.Errorf("open file: %v", err)
-		}
-		defer func() {
-			if closeErr := file.Close(); err == nil {
-				err = closeErr
-			}
-		}()
-		if _, err = io.Copy(source.buffer, file); err != nil {
-			return 0, fmt.Errorf("copy file: %v", err)
+			return 0, err
 		}
+		source.buffer.Write(data)
+	}
+
+	return readBuffer(source.buffer, p)
+}
+
+func (source *fileSource) ReadAll(ctx context.Context) (data []byte, err error) {
+	if err = ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	n, err = source.buffer.Read(p)
+	file, err := os.Open(source.filename)
 	if err != nil {
-		return n, err
+		return nil, fmt.Errorf("open file: %v", err)
 	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
 
-	if source.buffer.Len() == 0 {
-		source.buffer.Reset()
-		return n, io.EOF
+	if data, err = ioutil.ReadAll(file); err != nil {
+		return nil, fmt.Errorf("read file: %v", err)
 	}
 
-	return n, err
+	return data, nil
+}
+
+func (source *fileSource) Name() string {
+	return source.filename
 }
 
 func (source *fileSource) Close() error {
@@ -72,37 +89,64 @@ func NewURLSource(url string) Source {
 
 func (source *urlSource) Read(p []byte) (n int, err error) {
 	if source.buffer.Len() == 0 {
-		var resp *http.Response
-		resp, err = http.Get(source.url)
+		data, err := source.ReadAll(context.Background())
 		if err != nil {
-			return 0, fmt.Errorf("http get: %v", err)
-		}
-		defer func() {
-			if closeErr := resp.Body.Close(); err == nil {
-				err = closeErr
-			}
-		}()
-		if _, err = io.Copy(source.buffer, resp.Body); err != nil {
-			if err != nil {
-				return 0, fmt.Errorf("copy body: %v", err)
-			}
+			return 0, err
 		}
+		source.buffer.Write(data)
 	}
 
-	n, err = source.buffer.Read(p)
+	return readBuffer(source.buffer, p)
+}
+
+func (source *urlSource) ReadAll(ctx context.Context) (data []byte, err error) {
+	req, err := http.NewRequest(http.MethodGet, source.url, nil)
 	if err != nil {
-		return n, err
+		return nil, fmt.Errorf("new request: %v", err)
 	}
 
-	if source.buffer.Len() == 0 {
-		source.buffer.Reset()
-		return n, io.EOF
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("http get: %v", err)
 	}
+	defer func() {
+		if closeErr := resp.Body.Close(); err == nil {
+			err = closeErr
+		}
+	}()
 
-	return n, err
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("http get: unexpected status %s", resp.Status)
+	}
+
+	if data, err = ioutil.ReadAll(resp.Body); err != nil {
+		return nil, fmt.Errorf("read body: %v", err)
+	}
+
+	return data, nil
+}
+
+func (source *urlSource) Name() string {
+	return source.url
 }
 
 func (source *urlSource) Close() error {
 	source.buffer.Reset()
 	return nil
 }
+
+// readBuffer reads from the buffer and resets it once it is drained, so that
+// the next read loads the source again.
+func readBuffer(buffer *bytes.Buffer, p []byte) (n int, err error) {
+	n, err = buffer.Read(p)
+	if err != nil {
+		return n, err
+	}
+
+	if buffer.Len() == 0 {
+		buffer.Reset()
+		return n, io.EOF
+	}
+
+	return n, err
+}
@@ -1,6 +1,7 @@
 package dialogflow
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -63,3 +64,27 @@ func TestNewURLSource(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestURLSourceReadAllCancel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewURLSource(ts.URL).ReadAll(ctx); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestURLSourceReadAllNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	_, err := NewURLSource(ts.URL).ReadAll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "404 Not Found") {
+		t.Errorf("expected 404 error, got %v", err)
+	}
+}
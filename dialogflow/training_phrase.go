@@ -0,0 +1,249 @@
+package dialogflow
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// A training phrase annotates entities as @type:text or @type:'some text',
+// where the alias of the parameter is the last part of the entity type, as in
+// @sys.date:'next week' with the alias date. An explicit alias is given as
+// @type:alias='text'. Quoted text can contain the other quote, or the same
+// quote and backslashes escaped by a backslash. An @ that would start an
+// annotation is written as \@. An @type: without text or a quote after it is
+// text, as it was before quotes and aliases were supported.
+
+// trainingPhraseError is a syntax error in a training phrase at a column,
+// counted in characters from 1.
+type trainingPhraseError struct {
+	Column int
+	Msg    string
+}
+
+func (err *trainingPhraseError) Error() string {
+	return fmt.Sprintf("column %d: %s", err.Column, err.Msg)
+}
+
+type trainingPhraseParser struct {
+	phrase []rune
+	pos    int
+}
+
+func parseTrainingPhrase(trainingPhrase string) ([]TrainingPhrasePart, error) {
+	p := &trainingPhraseParser{phrase: []rune(trainingPhrase)}
+
+	var (
+		parts []TrainingPhrasePart
+		text  []rune
+	)
+	for p.pos < len(p.phrase) {
+		r := p.phrase[p.pos]
+		switch {
+		case r == '\\' && p.pos+1 < len(p.phrase) && (p.phrase[p.pos+1] == '@' || p.phrase[p.pos+1] == '\\'):
+			text = append(text, p.phrase[p.pos+1])
+			p.pos += 2
+		case r == '@' && isAnnotation(p.phrase, p.pos):
+			part, ok, err := p.parseAnnotation()
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				text = append(text, r)
+				p.pos++
+				continue
+			}
+			if len(text) > 0 {
+				parts = append(parts, TrainingPhrasePart{Text: string(text)})
+				text = nil
+			}
+			parts = append(parts, part)
+		default:
+			text = append(text, r)
+			p.pos++
+		}
+	}
+	if len(text) > 0 {
+		parts = append(parts, TrainingPhrasePart{Text: string(text)})
+	}
+
+	return parts, nil
+}
+
+// parseAnnotation parses an annotation that starts at the current position. It
+// reports false, leaving the position unchanged, if no text or quote follows
+// the entity type.
+func (p *trainingPhraseParser) parseAnnotation() (TrainingPhrasePart, bool, error) {
+	start := p.pos
+	p.pos++
+	for p.phrase[p.pos] != ':' {
+		p.pos++
+	}
+	entityType := string(p.phrase[start:p.pos])
+	p.pos++
+
+	alias := defaultAlias(entityType)
+	n := p.pos
+	for n < len(p.phrase) && isAliasRune(p.phrase[n]) {
+		n++
+	}
+	if n > p.pos && n < len(p.phrase) && p.phrase[n] == '=' {
+		alias = string(p.phrase[p.pos:n])
+		p.pos = n + 1
+	}
+
+	var text string
+	if p.pos < len(p.phrase) && (p.phrase[p.pos] == '\'' || p.phrase[p.pos] == '"') {
+		var err error
+		if text, err = p.parseQuoted(p.phrase[p.pos]); err != nil {
+			return TrainingPhrasePart{}, false, err
+		}
+		if text == "" {
+			return TrainingPhrasePart{}, false, p.errorf(p.pos-2, "empty text for %s", entityType)
+		}
+	} else {
+		n := p.pos
+		for n < len(p.phrase) && isWordRune(p.phrase[n]) {
+			n++
+		}
+		if n == p.pos {
+			p.pos = start
+			return TrainingPhrasePart{}, false, nil
+		}
+		text = string(p.phrase[p.pos:n])
+		p.pos = n
+	}
+
+	return TrainingPhrasePart{
+		Text:        text,
+		EntityType:  entityType,
+		Alias:       alias,
+		UserDefined: true,
+	}, true, nil
+}
+
+// parseQuoted parses the quoted text that starts at the current position.
+func (p *trainingPhraseParser) parseQuoted(quote rune) (string, error) {
+	start := p.pos
+	p.pos++
+
+	var text []rune
+	for p.pos < len(p.phrase) {
+		r := p.phrase[p.pos]
+		switch {
+		case r == '\\' && p.pos+1 < len(p.phrase) && isQuoteEscape(p.phrase[p.pos+1]):
+			text = append(text, p.phrase[p.pos+1])
+			p.pos += 2
+		case r == quote:
+			p.pos++
+			return string(text), nil
+		default:
+			text = append(text, r)
+			p.pos++
+		}
+	}
+
+	return "", p.errorf(start, "unterminated quote")
+}
+
+func (p *trainingPhraseParser) errorf(pos int, format string, args ...interface{}) error {
+	return &trainingPhraseError{Column: pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// isAnnotation reports whether s has an @, an entity type and a colon at
+// index i. An @ that follows a word rune, as in me@example.com:8080, does not
+// start an annotation.
+func isAnnotation(s []rune, i int) bool {
+	if i > 0 && isWordRune(s[i-1]) {
+		return false
+	}
+	s = s[i:]
+	if len(s) < 3 || s[0] != '@' || !isWordRune(s[1]) {
+		return false
+	}
+	for _, r := range s[2:] {
+		if r == ':' {
+			return true
+		}
+		if !isWordRune(r) && r != '.' && r != '-' {
+			return false
+		}
+	}
+	return false
+}
+
+// defaultAlias returns the alias of an entity type without an explicit alias,
+// the part after the last dot, such as date for @sys.date.
+func defaultAlias(entityType string) string {
+	alias := strings.TrimPrefix(entityType, "@")
+	if i := strings.LastIndex(alias, "."); i >= 0 {
+		alias = alias[i+1:]
+	}
+	return alias
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isAliasRune(r rune) bool {
+	return isWordRune(r) || r == '-'
+}
+
+func isQuoteEscape(r rune) bool {
+	return r == '\'' || r == '"' || r == '\\'
+}
+
+func formatTrainingPhrase(parts []TrainingPhrasePart) string {
+	var (
+		b    strings.Builder
+		text string
+	)
+	for _, p := range parts {
+		if p.EntityType == "" {
+			text += p.Text
+			continue
+		}
+		b.WriteString(escapeTrainingPhraseText(text))
+		text = ""
+
+		b.WriteString(p.EntityType)
+		b.WriteString(":")
+		if p.Alias != "" && p.Alias != defaultAlias(p.EntityType) {
+			b.WriteString(p.Alias)
+			b.WriteString("=")
+		}
+		b.WriteString(quoteTrainingPhraseText(p.Text))
+	}
+	b.WriteString(escapeTrainingPhraseText(text))
+	return b.String()
+}
+
+// escapeTrainingPhraseText escapes the @ that would start an annotation and
+// the backslashes that would escape it.
+func escapeTrainingPhraseText(text string) string {
+	s := []rune(text)
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '@' && isAnnotation(s, i):
+			b.WriteString(`\@`)
+		case r == '\\' && (i+1 == len(s) || s[i+1] == '@' || s[i+1] == '\\'):
+			b.WriteString(`\\`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// quoteTrainingPhraseText quotes the text of an annotation, preferring a quote
+// that the text does not contain.
+func quoteTrainingPhraseText(text string) string {
+	quote := "'"
+	if strings.Contains(text, quote) && !strings.Contains(text, `"`) {
+		quote = `"`
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, quote, `\`+quote)
+	return quote + replacer.Replace(text) + quote
+}
@@ -0,0 +1,132 @@
+package dialogflow
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTrainingPhrase(t *testing.T) {
+	tests := []struct {
+		phrase string
+		parts  []TrainingPhrasePart
+	}{
+		{
+			phrase: `See you @sys.date:tomorrow`,
+			parts: []TrainingPhrasePart{
+				{Text: "See you "},
+				{Text: "tomorrow", EntityType: "@sys.date", Alias: "date", UserDefined: true},
+			},
+		},
+		{
+			phrase: `See you @sys.date:when='next week'`,
+			parts: []TrainingPhrasePart{
+				{Text: "See you "},
+				{Text: "next week", EntityType: "@sys.date", Alias: "when", UserDefined: true},
+			},
+		},
+		{
+			phrase: `I am @sys.given-name:'John'`,
+			parts: []TrainingPhrasePart{
+				{Text: "I am "},
+				{Text: "John", EntityType: "@sys.given-name", Alias: "given-name", UserDefined: true},
+			},
+		},
+		{
+			phrase: `Mail \@support:now or me@example.com`,
+			parts: []TrainingPhrasePart{
+				{Text: "Mail @support:now or me@example.com"},
+			},
+		},
+		{
+			phrase: `Play @song:"Don't stop" and @song:other='Say \'hi\' \\o/'`,
+			parts: []TrainingPhrasePart{
+				{Text: "Play "},
+				{Text: "Don't stop", EntityType: "@song", Alias: "song", UserDefined: true},
+				{Text: " and "},
+				{Text: `Say 'hi' \o/`, EntityType: "@song", Alias: "other", UserDefined: true},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		parts, err := parseTrainingPhrase(test.phrase)
+		if err != nil {
+			t.Errorf("%s: %v", test.phrase, err)
+			continue
+		}
+		if !reflect.DeepEqual(test.parts, parts) {
+			t.Errorf("%s: expected %+v, got %+v", test.phrase, test.parts, parts)
+		}
+
+		formatted, err := parseTrainingPhrase(formatTrainingPhrase(parts))
+		if err != nil {
+			t.Errorf("%s: %v", formatTrainingPhrase(parts), err)
+			continue
+		}
+		if !reflect.DeepEqual(parts, formatted) {
+			t.Errorf("%s: expected %+v, got %+v", formatTrainingPhrase(parts), parts, formatted)
+		}
+	}
+}
+
+func TestParseTrainingPhraseText(t *testing.T) {
+	tests := []string{
+		`email @home: now`,
+		`mail me@example.com:8080`,
+		`mail me@home:now`,
+		`See you @sys.date:`,
+		`See you @sys.date: tomorrow`,
+		`See you @sys.date:=tomorrow`,
+		`See you @sys.date:date= tomorrow`,
+	}
+
+	for _, test := range tests {
+		parts, err := parseTrainingPhrase(test)
+		if err != nil {
+			t.Errorf("%s: %v", test, err)
+			continue
+		}
+		if expected := []TrainingPhrasePart{{Text: test}}; !reflect.DeepEqual(expected, parts) {
+			t.Errorf("%s: expected %+v, got %+v", test, expected, parts)
+		}
+	}
+
+	if formatted := formatTrainingPhrase([]TrainingPhrasePart{{Text: "mail me@example.com:8080"}}); formatted != "mail me@example.com:8080" {
+		t.Errorf("expected mail me@example.com:8080, got %s", formatted)
+	}
+
+	parts, err := parseTrainingPhrase(`mail @home: now to @person:John`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []TrainingPhrasePart{
+		{Text: "mail @home: now to "},
+		{Text: "John", EntityType: "@person", Alias: "person", UserDefined: true},
+	}
+	if !reflect.DeepEqual(expected, parts) {
+		t.Errorf("expected %+v, got %+v", expected, parts)
+	}
+}
+
+func TestParseTrainingPhraseErrors(t *testing.T) {
+	tests := []struct {
+		phrase string
+		column int
+	}{
+		{phrase: `See you @sys.date:'tomorrow`, column: 19},
+		{phrase: `See you @sys.date:''`, column: 19},
+		{phrase: `See you @sys.date:date=''`, column: 24},
+	}
+
+	for _, test := range tests {
+		_, err := parseTrainingPhrase(test.phrase)
+		phraseErr, ok := err.(*trainingPhraseError)
+		if !ok {
+			t.Errorf("%s: expected training phrase error, got %v", test.phrase, err)
+			continue
+		}
+		if phraseErr.Column != test.column {
+			t.Errorf("%s: expected column %d, got %d", test.phrase, test.column, phraseErr.Column)
+		}
+	}
+}
@@ -5,8 +5,12 @@ go 1.12
 require (
 	cloud.google.com/go v0.47.0
 	github.com/ghodss/yaml v1.0.0
+	github.com/golang/protobuf v1.3.2
 	github.com/spf13/cobra v0.0.5
+	github.com/spf13/pflag v1.0.3
 	google.golang.org/api v0.11.0
 	google.golang.org/genproto v0.0.0-20191009194640-548a555dbc03
+	google.golang.org/grpc v1.21.1
 	gopkg.in/yaml.v2 v2.2.4 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )